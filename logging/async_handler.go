@@ -0,0 +1,246 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/exp/slog"
+)
+
+// ErrHandlerClosed is returned when a record is handled by an AsyncHandler
+// that has been closed.
+var ErrHandlerClosed = errors.New("logging: handler closed")
+
+// OverflowPolicy determines what an AsyncHandler does with a record when its
+// queue is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the caller until there is room in the queue.
+	OverflowBlock OverflowPolicy = iota
+
+	// OverflowDropNewest drops the record being handled.
+	OverflowDropNewest
+
+	// OverflowDropLowest drops the oldest queued record with the lowest
+	// level, or the record being handled if its level is no higher.
+	OverflowDropLowest
+)
+
+// AsyncHandler is a handler that queues records to be passed to another
+// handler by a background goroutine, so that writing log entries does not add
+// latency to the caller. Once the queue is full records are blocked or dropped
+// according to the OverflowPolicy.
+//
+// Errors returned by the underlying handler are discarded. Flush waits for the
+// queue to drain and Close should be called during shutdown so that queued
+// records are not lost.
+type AsyncHandler struct {
+	handler slog.Handler
+	queue   *asyncQueue
+}
+
+// asyncQueue holds the state shared by an AsyncHandler and the handlers
+// derived from it.
+type asyncQueue struct {
+	policy OverflowPolicy
+	size   int
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	records  []asyncRecord
+	closed   bool
+
+	// busy is set while the background goroutine is handling a record, and
+	// idle is closed once the queue is empty and the goroutine is not busy.
+	busy bool
+	idle chan struct{}
+
+	// done is closed once the background goroutine has exited.
+	done chan struct{}
+
+	dropped atomic.Int64
+}
+
+type asyncRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+// NewAsyncHandler returns a new AsyncHandler passing records to h. At most
+// size records are queued, after which the policy applies.
+func NewAsyncHandler(h slog.Handler, size int, policy OverflowPolicy) *AsyncHandler {
+	if size < 1 {
+		size = 1
+	}
+
+	q := &asyncQueue{
+		policy: policy,
+		size:   size,
+		idle:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	close(q.idle)
+
+	go q.run()
+
+	return &AsyncHandler{
+		handler: h,
+		queue:   q,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle queues r to be passed to the underlying handler. It returns
+// ErrHandlerClosed if the handler has been closed.
+func (h *AsyncHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.queue.push(asyncRecord{ctx: ctx, handler: h.handler, record: r.Clone()})
+}
+
+// WithAttrs returns a new AsyncHandler whose attributes consists of h's
+// attributes followed by attrs. The new handler shares the queue of h.
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{handler: h.handler.WithAttrs(attrs), queue: h.queue}
+}
+
+// WithGroup returns a new AsyncHandler whose attributes consists of h's
+// attributes followed by a group with the given name. The new handler shares
+// the queue of h.
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{handler: h.handler.WithGroup(name), queue: h.queue}
+}
+
+// Dropped returns the number of records dropped because the queue was full.
+func (h *AsyncHandler) Dropped() int64 {
+	return h.queue.dropped.Load()
+}
+
+// Flush waits until the queue is empty and every record has been passed to
+// the underlying handler, or until ctx is done.
+func (h *AsyncHandler) Flush(ctx context.Context) error {
+	h.queue.mu.Lock()
+	idle := h.queue.idle
+	h.queue.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the handler accepting records and waits until those already
+// queued have been passed to the underlying handler, or until ctx is done.
+// The queue continues to drain in the background should ctx be done first.
+func (h *AsyncHandler) Close(ctx context.Context) error {
+	q := h.queue
+
+	q.mu.Lock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// push adds ar to the queue, applying the overflow policy if it is full.
+func (q *asyncQueue) push(ar asyncRecord) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.policy == OverflowBlock {
+		for len(q.records) >= q.size && !q.closed {
+			q.notFull.Wait()
+		}
+	}
+
+	if q.closed {
+		return ErrHandlerClosed
+	}
+
+	if len(q.records) >= q.size {
+		q.dropped.Add(1)
+
+		if q.policy != OverflowDropLowest {
+			return nil
+		}
+
+		i := q.lowest()
+		if ar.record.Level <= q.records[i].record.Level {
+			return nil
+		}
+		q.records = append(q.records[:i], q.records[i+1:]...)
+	}
+
+	// The queue is no longer idle once a record is added to it.
+	if len(q.records) == 0 && !q.busy {
+		q.idle = make(chan struct{})
+	}
+
+	q.records = append(q.records, ar)
+	q.notEmpty.Signal()
+
+	return nil
+}
+
+// lowest returns the index of the oldest queued record with the lowest level.
+func (q *asyncQueue) lowest() int {
+	i := 0
+	for j := range q.records {
+		if q.records[j].record.Level < q.records[i].record.Level {
+			i = j
+		}
+	}
+	return i
+}
+
+// run passes queued records to their handler until the queue is closed and
+// empty.
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		for len(q.records) == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+
+		if len(q.records) == 0 {
+			return
+		}
+
+		ar := q.records[0]
+		q.records[0] = asyncRecord{}
+		q.records = q.records[1:]
+		q.busy = true
+		q.notFull.Signal()
+		q.mu.Unlock()
+
+		_ = ar.handler.Handle(ar.ctx, ar.record)
+
+		q.mu.Lock()
+		q.busy = false
+		if len(q.records) == 0 {
+			close(q.idle)
+		}
+	}
+}
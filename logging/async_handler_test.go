@@ -0,0 +1,179 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+// gatedWriter is a writer that blocks until the gate is opened, signalling
+// entered as each write begins.
+type gatedWriter struct {
+	gate    chan struct{}
+	entered chan struct{}
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) Bytes() []byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Bytes()
+}
+
+// newGatedAsyncHandler returns an AsyncHandler whose background goroutine is
+// blocked writing the first record until the gate is opened.
+func newGatedAsyncHandler(t *testing.T, size int, policy logging.OverflowPolicy) (*logging.AsyncHandler, *gatedWriter) {
+	w := &gatedWriter{gate: make(chan struct{}), entered: make(chan struct{}, 1)}
+	h := logging.NewAsyncHandler(slog.NewJSONHandler(w), size, policy)
+
+	logger := logging.New(h)
+	logger.Info(context.Background(), "hailing")
+
+	// Wait for the background goroutine to take the first record from the
+	// queue.
+	select {
+	case <-w.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("first record was not written")
+	}
+
+	return h, w
+}
+
+func TestAsyncHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes records in the background", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := logging.NewAsyncHandler(slog.NewJSONHandler(buf), 10, logging.OverflowBlock)
+
+		logger := logging.New(h).With("captain", "picard")
+		logger.Info(context.Background(), "engage")
+
+		if err := h.Close(context.Background()); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		entries := decodeLogEntries(t, buf.Bytes())
+		assertJSON(t, "messages", messages(t, buf.Bytes()), `["engage"]`)
+		assertJSON(t, "captain", entries[0]["captain"], `"picard"`)
+	})
+
+	t.Run("drops the newest records when the queue is full", func(t *testing.T) {
+		h, w := newGatedAsyncHandler(t, 1, logging.OverflowDropNewest)
+
+		logger := logging.New(h)
+		logger.Info(context.Background(), "engage")
+		logger.Error(context.Background(), "warp core breach")
+
+		close(w.gate)
+		if err := h.Flush(context.Background()); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		assertJSON(t, "messages", messages(t, w.Bytes()), `["hailing","engage"]`)
+		if h.Dropped() != 1 {
+			t.Errorf("dropped records are not equal: %d != %d", h.Dropped(), 1)
+		}
+	})
+
+	t.Run("drops the lowest level records when the queue is full", func(t *testing.T) {
+		h, w := newGatedAsyncHandler(t, 2, logging.OverflowDropLowest)
+
+		logger := logging.New(h)
+		logger.Info(context.Background(), "engage")
+		logger.Warning(context.Background(), "shields down")
+		logger.Error(context.Background(), "warp core breach")
+		logger.Notice(context.Background(), "scanning")
+
+		close(w.gate)
+		if err := h.Flush(context.Background()); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		assertJSON(t, "messages", messages(t, w.Bytes()), `["hailing","shields down","warp core breach"]`)
+		if h.Dropped() != 2 {
+			t.Errorf("dropped records are not equal: %d != %d", h.Dropped(), 2)
+		}
+	})
+
+	t.Run("blocks until there is room in the queue", func(t *testing.T) {
+		h, w := newGatedAsyncHandler(t, 1, logging.OverflowBlock)
+
+		logger := logging.New(h)
+		logger.Info(context.Background(), "engage")
+
+		handled := make(chan struct{})
+		go func() {
+			logger.Info(context.Background(), "make it so")
+			close(handled)
+		}()
+
+		select {
+		case <-handled:
+			t.Fatal("record was handled while the queue was full")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		close(w.gate)
+		<-handled
+
+		if err := h.Close(context.Background()); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		assertJSON(t, "messages", messages(t, w.Bytes()), `["hailing","engage","make it so"]`)
+		if h.Dropped() != 0 {
+			t.Errorf("dropped records are not equal: %d != %d", h.Dropped(), 0)
+		}
+	})
+
+	t.Run("returns when the context is done before the queue is flushed", func(t *testing.T) {
+		h, w := newGatedAsyncHandler(t, 1, logging.OverflowBlock)
+		defer close(w.gate)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if err := h.Flush(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("errors are not equal: %v != %s", err, context.Canceled)
+		}
+		if err := h.Close(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("errors are not equal: %v != %s", err, context.Canceled)
+		}
+	})
+
+	t.Run("returns an error once closed", func(t *testing.T) {
+		h := logging.NewAsyncHandler(slog.NewJSONHandler(&bytes.Buffer{}), 1, logging.OverflowBlock)
+
+		if err := h.Close(context.Background()); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		err := h.Handle(context.Background(), slog.NewRecord(time.Now(), logging.LevelInfo, "engage", 0))
+		if !errors.Is(err, logging.ErrHandlerClosed) {
+			t.Errorf("errors are not equal: %v != %s", err, logging.ErrHandlerClosed)
+		}
+	})
+}
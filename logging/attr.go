@@ -2,6 +2,8 @@ package logging
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"golang.org/x/exp/slog"
 )
@@ -39,3 +41,28 @@ func severityValue(v slog.Value) slog.Value {
 		return slog.StringValue("DEFAULT")
 	}
 }
+
+// latencyValue formats a duration in the format expected by the latency field
+// of a Google Cloud Logging HttpRequest, that is in seconds with up to nine
+// fractional digits and terminated by "s".
+func latencyValue(v slog.Value) string {
+	d, ok := durationValue(v)
+	if !ok {
+		return v.String()
+	}
+
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// durationValue returns the duration held by v, which may have been logged
+// either as a duration or as an integer number of nanoseconds.
+func durationValue(v slog.Value) (time.Duration, bool) {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration(), true
+	case slog.KindInt64:
+		return time.Duration(v.Int64()), true
+	default:
+		return 0, false
+	}
+}
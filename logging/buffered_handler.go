@@ -0,0 +1,185 @@
+package logging
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// bufferedDroppedMsg is the message of the record written when a request's
+// buffer overflowed before it was flushed.
+const bufferedDroppedMsg = "buffered log records dropped"
+
+// logBuffer holds the records logged below a threshold level during a single
+// request. Once the buffer is full the oldest records are dropped.
+type logBuffer struct {
+	handler slog.Handler
+	max     int
+
+	mu      sync.Mutex
+	records []bufferedRecord
+	start   int
+	dropped int
+
+	// Once the request has finished records are no longer buffered. They are
+	// either handled immediately, if the buffer was flushed, or dropped.
+	done    bool
+	flushed bool
+}
+
+type bufferedRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+}
+
+func newLogBuffer(h slog.Handler, max int) *logBuffer {
+	return &logBuffer{
+		handler: h,
+		max:     max,
+	}
+}
+
+// add buffers r to be handled by h should the buffer be flushed. It reports
+// false if the request has finished, in which case r should instead be
+// handled immediately, or dropped, according to flushed.
+func (b *logBuffer) add(ctx context.Context, h slog.Handler, r slog.Record) (buffered, flushed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.done {
+		return false, b.flushed
+	}
+
+	br := bufferedRecord{ctx: ctx, handler: h, record: r.Clone()}
+
+	switch {
+	case b.max <= 0:
+		b.dropped++
+	case len(b.records) < b.max:
+		b.records = append(b.records, br)
+	default:
+		// Overwrite the oldest record.
+		b.records[b.start] = br
+		b.start = (b.start + 1) % b.max
+		b.dropped++
+	}
+
+	return true, false
+}
+
+// flush handles every buffered record, in the order they were logged, and
+// handles any record logged afterwards immediately. If records were dropped
+// a record reporting how many is handled first. Flushing a nil buffer does
+// nothing.
+func (b *logBuffer) flush(ctx context.Context) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.done {
+		return
+	}
+	b.done, b.flushed = true, true
+
+	if b.dropped > 0 {
+		record := slog.NewRecord(time.Now(), LevelWarning, bufferedDroppedMsg, 0)
+		record.AddAttrs(slog.Int(samplingDroppedKey, b.dropped))
+		b.handler.Handle(ctx, record)
+	}
+
+	for i := range b.records {
+		br := b.records[(b.start+i)%len(b.records)]
+		br.handler.Handle(br.ctx, br.record)
+	}
+
+	b.records = nil
+}
+
+// discard drops every buffered record, along with any record logged
+// afterwards. Discarding a nil buffer does nothing.
+func (b *logBuffer) discard() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.done = true
+	b.records = nil
+}
+
+// flushOnPanic flushes the buffer if the calling function is panicking, then
+// continues to panic. It must be called directly by a deferred call.
+func flushOnPanic(ctx context.Context, b *logBuffer) {
+	if p := recover(); p != nil {
+		b.flush(ctx)
+		panic(p)
+	}
+}
+
+// bufferedHandler is a handler that holds records below a threshold level in
+// a logBuffer, passing all other records to the underlying handler.
+type bufferedHandler struct {
+	handler   slog.Handler
+	threshold slog.Level
+	buffer    *logBuffer
+}
+
+// Enabled reports whether the handler handles records at the given level.
+// Records below the threshold are always enabled since they are written only
+// if the buffer is flushed.
+func (h *bufferedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level < h.threshold || h.handler.Enabled(ctx, level)
+}
+
+// Handle buffers r if it is below the threshold, otherwise passes it to the
+// underlying handler.
+func (h *bufferedHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= h.threshold {
+		return h.handler.Handle(ctx, r)
+	}
+
+	buffered, flushed := h.buffer.add(ctx, h.handler, r)
+	if buffered || !flushed {
+		return nil
+	}
+
+	return h.handler.Handle(ctx, r)
+}
+
+// WithAttrs returns a new bufferedHandler whose attributes consists of h's
+// attributes followed by attrs. The new handler shares the buffer of h.
+func (h *bufferedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &bufferedHandler{handler: h.handler.WithAttrs(attrs), threshold: h.threshold, buffer: h.buffer}
+}
+
+// WithGroup returns a new bufferedHandler whose attributes consists of h's
+// attributes followed by a group with the given name. The new handler shares
+// the buffer of h.
+func (h *bufferedHandler) WithGroup(name string) slog.Handler {
+	return &bufferedHandler{handler: h.handler.WithGroup(name), threshold: h.threshold, buffer: h.buffer}
+}
+
+// requestHandler returns the handler of the logger scoped to a single
+// request, along with the buffer holding its records if buffering is
+// enabled.
+func (o options) requestHandler() (slog.Handler, *logBuffer) {
+	if !o.bufferLogs {
+		return o.handler, nil
+	}
+
+	buffer := newLogBuffer(o.handler, o.maxBufferedRecords)
+
+	return &bufferedHandler{
+		handler:   o.handler,
+		threshold: o.bufferThreshold,
+		buffer:    buffer,
+	}, buffer
+}
@@ -0,0 +1,123 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kapetndev/connect/logging"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+)
+
+func messages(t *testing.T, b []byte) []string {
+	var msgs []string
+	for _, entry := range decodeLogEntries(t, b) {
+		msgs = append(msgs, entry["msg"].(string))
+	}
+	return msgs
+}
+
+func TestWithLogBuffering_RequestLogger(t *testing.T) {
+	t.Parallel()
+
+	serve := func(statusCode int, opts ...logging.Option) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		h := slog.HandlerOptions{Level: logging.LevelInfo}.NewJSONHandler(buf)
+
+		mw := logging.RequestLogger(append([]logging.Option{logging.WithHandler(h)}, opts...)...)
+		mw(func(w http.ResponseWriter, r *http.Request) {
+			logger := logging.FromContext(r.Context())
+			logger.Debug(r.Context(), "scanning")
+			logger.Trace(r.Context(), "scanning deeper")
+			logger.Info(r.Context(), "engaging")
+			w.WriteHeader(statusCode)
+		})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bridge", nil))
+
+		return buf
+	}
+
+	t.Run("discards buffered records when the request succeeds", func(t *testing.T) {
+		buf := serve(http.StatusNotFound, logging.WithLogBuffering(logging.LevelInfo, 10))
+		assertJSON(t, "messages", messages(t, buf.Bytes()), `["engaging",""]`)
+	})
+
+	t.Run("writes buffered records when the request fails", func(t *testing.T) {
+		buf := serve(http.StatusServiceUnavailable, logging.WithLogBuffering(logging.LevelInfo, 10))
+		assertJSON(t, "messages", messages(t, buf.Bytes()), `["engaging","scanning","scanning deeper",""]`)
+	})
+
+	t.Run("drops the oldest records once the buffer is full", func(t *testing.T) {
+		buf := serve(http.StatusInternalServerError, logging.WithLogBuffering(logging.LevelInfo, 1))
+		assertJSON(t, "messages", messages(t, buf.Bytes()), `["engaging","buffered log records dropped","scanning deeper",""]`)
+	})
+}
+
+func TestWithLogBuffering_Interceptor(t *testing.T) {
+	t.Parallel()
+
+	invoke := func(handler grpc.UnaryHandler) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+
+		interceptor := logging.UnaryServerInterceptor(
+			logging.WithHandler(slog.NewJSONHandler(buf)),
+			logging.WithLogBuffering(logging.LevelInfo, 10),
+		)
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/echo.v1.EchoService/Echo"}
+		_, _ = interceptor(context.Background(), &echopb.EchoRequest{}, info, handler)
+
+		return buf
+	}
+
+	t.Run("writes buffered records when the call returns an error", func(t *testing.T) {
+		buf := invoke(func(ctx context.Context, req interface{}) (interface{}, error) {
+			logging.FromContext(ctx).Debug(ctx, "scanning")
+			return nil, status.Error(codes.Internal, "warp core breach")
+		})
+
+		assertJSON(t, "messages", messages(t, buf.Bytes()), `["scanning",""]`)
+	})
+
+	t.Run("writes buffered records when the handler panics", func(t *testing.T) {
+		var buf *bytes.Buffer
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("panic was not propagated")
+				}
+			}()
+
+			buf = &bytes.Buffer{}
+			interceptor := logging.UnaryServerInterceptor(
+				logging.WithHandler(slog.NewJSONHandler(buf)),
+				logging.WithLogBuffering(logging.LevelInfo, 10),
+			)
+
+			info := &grpc.UnaryServerInfo{FullMethod: "/echo.v1.EchoService/Echo"}
+			_, _ = interceptor(context.Background(), &echopb.EchoRequest{}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				logging.FromContext(ctx).Debug(ctx, "scanning")
+				panic("warp core breach")
+			})
+		}()
+
+		assertJSON(t, "messages", messages(t, buf.Bytes()), `["scanning"]`)
+	})
+
+	t.Run("discards buffered records when the call succeeds", func(t *testing.T) {
+		buf := invoke(func(ctx context.Context, req interface{}) (interface{}, error) {
+			logging.FromContext(ctx).Debug(ctx, "scanning")
+			return &echopb.EchoResponse{}, nil
+		})
+
+		assertJSON(t, "messages", messages(t, buf.Bytes()), `[""]`)
+	})
+}
@@ -0,0 +1,223 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor is a client side unary interceptor logging the
+// payloads for a single outbound request/response.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	o := applyOptions(opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		startTime := time.Now()
+
+		// Apply any rules matching the method.
+		o := o.forRoute(method)
+
+		// Invoke the remote method and log the response.
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+
+		// Suppress request logs matching some pattern.
+		if !o.sampled() || o.shouldDiscard(ctx, method, err) {
+			return err
+		}
+
+		if err != nil {
+			record := newRPCErrorRecord(ctx, o.codeLevel(status.Code(err)), startTime, kindClient, method, err)
+			record.AddAttrs(o.requestAttrs(req)...)
+			o.handle(ctx, record)
+			return err
+		}
+
+		// Log the request/response.
+		record := newRPCRecord(ctx, o.codeLevel(codes.OK), startTime, kindClient, method)
+		record.AddAttrs(o.responseAttrs(reply)...)
+		record.AddAttrs(o.requestAttrs(req)...)
+		o.handle(ctx, record)
+		return err
+	}
+}
+
+// StreamClientInterceptor is a client side stream interceptor logging a
+// single outbound stream. Like the server side interceptor the payload of
+// each message in the stream is collected and logged together, subject to
+// WithStreamPayloadLimit. The log entry is written once the stream has
+// finished for any reason, such as the server closing the stream, an error
+// being received or sent, the stream being cancelled or its connection being
+// closed. A caller which stops receiving before the stream has ended must
+// cancel its context, as gRPC requires, for the entry to be written.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	o := applyOptions(opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		startTime := time.Now()
+
+		// Apply any rules matching the method.
+		o := o.forRoute(method)
+		sampled := o.sampled()
+
+		// Streams that will not be logged need not be wrapped.
+		if !sampled {
+			return streamer(ctx, desc, cc, method, callOpts...)
+		}
+
+		s := &clientStream{
+			streamCapture: newStreamCapture(o, sampled),
+			ctx:           ctx,
+			desc:          desc,
+			method:        method,
+			opts:          o,
+			startTime:     startTime,
+		}
+
+		// gRPC reports the status of the stream once it has finished, however
+		// it ends. The entry may not be written from the callback itself, which
+		// is called while the stream is locked.
+		callOpts = append(callOpts, grpc.OnFinish(s.onFinish))
+
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			// Suppress request logs matching some pattern.
+			if !o.shouldDiscard(ctx, method, err) {
+				o.handle(ctx, newRPCErrorRecord(ctx, o.codeLevel(status.Code(err)), startTime, kindClient, method, err))
+			}
+
+			return cs, err
+		}
+		s.ClientStream = cs
+
+		// The context of the stream is done once the stream has finished, even
+		// if the caller never receives its final message.
+		go func() {
+			<-cs.Context().Done()
+			s.finish(s.result())
+		}()
+
+		return s, nil
+	}
+}
+
+// clientStream wraps a client side stream capturing the payload of every
+// message sent as a request and every message received as a response, so
+// that a log entry may be written when the stream finishes.
+type clientStream struct {
+	*streamCapture
+	grpc.ClientStream
+
+	ctx       context.Context
+	desc      *grpc.StreamDesc
+	method    string
+	opts      options
+	startTime time.Time
+
+	mu       sync.Mutex
+	err      error
+	finished bool
+
+	once sync.Once
+}
+
+// SendMsg sends a message, capturing its payload on success.
+func (cs *clientStream) SendMsg(m interface{}) error {
+	err := cs.ClientStream.SendMsg(m)
+
+	switch {
+	case err == nil:
+		cs.request(streamDirectionSend, m)
+	case !errors.Is(err, io.EOF):
+		// An io.EOF only signals that the stream has ended, with its status
+		// being returned by RecvMsg.
+		cs.finish(err)
+	}
+
+	return err
+}
+
+// RecvMsg blocks until it receives a message into m or the stream is done.
+func (cs *clientStream) RecvMsg(m interface{}) error {
+	err := cs.ClientStream.RecvMsg(m)
+
+	switch {
+	case errors.Is(err, io.EOF):
+		// The server has closed the stream successfully.
+		cs.finish(nil)
+	case err != nil:
+		cs.finish(err)
+	default:
+		cs.response(streamDirectionRecv, m)
+
+		// When the server does not stream its response only a single message
+		// is ever received, so the stream is complete once it has arrived.
+		if !cs.desc.ServerStreams {
+			cs.finish(nil)
+		}
+	}
+
+	return err
+}
+
+// Header returns the header metadata sent by the server.
+func (cs *clientStream) Header() (metadata.MD, error) {
+	md, err := cs.ClientStream.Header()
+	if err != nil {
+		cs.finish(err)
+	}
+	return md, err
+}
+
+// onFinish records the status of the finished stream, as reported by gRPC.
+func (cs *clientStream) onFinish(err error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.err = err
+	cs.finished = true
+}
+
+// result returns the status of the stream, which is that of its context
+// should gRPC not have reported one.
+func (cs *clientStream) result() error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if !cs.finished {
+		return status.FromContextError(cs.ctx.Err()).Err()
+	}
+	return cs.err
+}
+
+// finish writes the log entry of the stream, unless it has already been
+// written.
+func (cs *clientStream) finish(err error) {
+	cs.once.Do(func() {
+		ctx := cs.ctx
+
+		// Suppress request logs matching some pattern.
+		if cs.opts.shouldDiscard(ctx, cs.method, err) {
+			return
+		}
+
+		if err != nil {
+			record := newRPCErrorRecord(ctx, cs.opts.codeLevel(status.Code(err)), cs.startTime, kindClient, cs.method, err)
+			record.AddAttrs(cs.sizeAttrs()...)
+			record.AddAttrs(cs.opts.requestAttrs(cs.requests)...)
+			cs.opts.handle(ctx, record)
+			return
+		}
+
+		// Log the request/response.
+		record := newRPCRecord(ctx, cs.opts.codeLevel(codes.OK), cs.startTime, kindClient, cs.method)
+		record.AddAttrs(cs.sizeAttrs()...)
+		record.AddAttrs(cs.opts.responseAttrs(cs.responses)...)
+		record.AddAttrs(cs.opts.requestAttrs(cs.requests)...)
+		cs.opts.handle(ctx, record)
+	})
+}
@@ -0,0 +1,254 @@
+package logging_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kapetndev/connect/logging"
+	"github.com/kapetndev/connect/logging/logtest"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+	"github.com/kapetndev/grpctest"
+)
+
+// holdServer is an echo server whose server streaming responses are held
+// open, after their first message, until the call is cancelled.
+type holdServer struct {
+	logtest.EchoServer
+}
+
+// ServerStreamingEcho responds with the message of the request, then waits for
+// the call to be cancelled.
+func (s *holdServer) ServerStreamingEcho(in *echopb.ServerStreamingEchoRequest, ss echopb.EchoService_ServerStreamingEchoServer) error {
+	if err := ss.Send(&echopb.ServerStreamingEchoResponse{Message: in.Message}); err != nil {
+		return err
+	}
+	<-ss.Context().Done()
+	return ss.Context().Err()
+}
+
+// assertClientRecord asserts that h captured a single record of a call to
+// method, made by the client, which resulted in code.
+func assertClientRecord(t *testing.T, h *logtest.Handler, method string, code codes.Code) logtest.Record {
+	t.Helper()
+
+	records := h.Records()
+	if len(records) != 1 {
+		t.Fatalf("numbers of records are not equal: %d != %d", len(records), 1)
+	}
+
+	r := logtest.AssertLogged(t, h,
+		logtest.Path(method),
+		logtest.Attr(logging.KindKey, "client"),
+		logtest.Code(code),
+	)
+	if _, ok := r.Attr(logging.DurationKey); !ok {
+		t.Errorf("record has no duration: %v", r.Attrs)
+	}
+
+	return r
+}
+
+// waitForRecords waits until h has captured n records, or five seconds have
+// passed.
+func waitForRecords(t *testing.T, h *logtest.Handler, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(h.Records()) < n && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("logs the call with its response", func(t *testing.T) {
+		closer, client, h := logtest.NewClient(t, nil, logging.WithPayloadCapture(true))
+		defer closer()
+
+		if _, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: "engage"}); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		assertClientRecord(t, h, "/echo.v1.EchoService/Echo", codes.OK)
+		logtest.AssertLogged(t, h,
+			logtest.RequestField("message", "engage"),
+			logtest.ResponseField("message", "engage"),
+		)
+	})
+
+	t.Run("logs the code of a failed call", func(t *testing.T) {
+		closer, client, h := logtest.NewClient(t, nil)
+		defer closer()
+
+		if _, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: "InvalidArgument"}); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("error codes are not equal: %s != %s", status.Code(err), codes.InvalidArgument)
+		}
+
+		r := assertClientRecord(t, h, "/echo.v1.EchoService/Echo", codes.InvalidArgument)
+		if r.Level != logging.LevelWarning {
+			t.Errorf("levels are not equal: %s != %s", r.Level, logging.LevelWarning)
+		}
+	})
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("logs a server streaming call once the server closes the stream", func(t *testing.T) {
+		closer, client, h := logtest.NewClient(t, nil)
+		defer closer()
+
+		stream, err := client.ServerStreamingEcho(context.Background(), &echopb.ServerStreamingEchoRequest{Message: "engage"})
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		for i := 0; i < 3; i++ {
+			if _, err := stream.Recv(); err != nil {
+				t.Fatalf("error was not <nil>: %s", err)
+			}
+			if records := h.Records(); len(records) != 0 {
+				t.Fatalf("numbers of records are not equal: %d != %d", len(records), 0)
+			}
+		}
+
+		if _, err := stream.Recv(); !errors.Is(err, io.EOF) {
+			t.Fatalf("errors are not equal: %v != %s", err, io.EOF)
+		}
+
+		assertClientRecord(t, h, "/echo.v1.EchoService/ServerStreamingEcho", codes.OK)
+	})
+
+	t.Run("logs a client streaming call once the response is received", func(t *testing.T) {
+		closer, client, h := logtest.NewClient(t, nil, logging.WithPayloadCapture(true))
+		defer closer()
+
+		stream, err := client.ClientStreamingEcho(context.Background())
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		for _, msg := range []string{"make", "it", "so"} {
+			if err := stream.Send(&echopb.ClientStreamingEchoRequest{Message: msg}); err != nil {
+				t.Fatalf("error was not <nil>: %s", err)
+			}
+		}
+
+		if _, err := stream.CloseAndRecv(); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		r := assertClientRecord(t, h, "/echo.v1.EchoService/ClientStreamingEcho", codes.OK)
+
+		requests, _ := streamPayload(t, r, logging.RequestKey)
+		if want := []string{"send:1", "send:2", "send:3"}; !reflect.DeepEqual(requests, want) {
+			t.Errorf("request messages are not equal: %v != %v", requests, want)
+		}
+		responses, _ := streamPayload(t, r, logging.ResponseKey)
+		if want := []string{"recv:4"}; !reflect.DeepEqual(responses, want) {
+			t.Errorf("response messages are not equal: %v != %v", responses, want)
+		}
+	})
+
+	t.Run("captures the messages of a stream subject to the limits", func(t *testing.T) {
+		closer, client, h := logtest.NewClient(t, nil, logging.WithStreamPayloadLimit(2, 1<<10))
+		defer closer()
+
+		stream, err := client.ServerStreamingEcho(context.Background(), &echopb.ServerStreamingEchoRequest{Message: "engage"})
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		for {
+			if _, err := stream.Recv(); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				t.Fatalf("error was not <nil>: %s", err)
+			}
+		}
+
+		r := assertClientRecord(t, h, "/echo.v1.EchoService/ServerStreamingEcho", codes.OK)
+
+		// Without request payloads the messages sent are captured along with
+		// those received.
+		responses, truncated := streamPayload(t, r, logging.ResponseKey)
+		if want := []string{"send:1", "recv:2"}; !reflect.DeepEqual(responses, want) {
+			t.Errorf("response messages are not equal: %v != %v", responses, want)
+		}
+		if !truncated {
+			t.Error("response payload was not truncated")
+		}
+		if size, _ := r.Attr(logging.ResponseSizeKey); size != int64(24) {
+			t.Errorf("response sizes are not equal: %v != %d", size, 24)
+		}
+	})
+
+	t.Run("logs a stream whose connection is closed", func(t *testing.T) {
+		h := logtest.NewHandler(nil)
+
+		s := grpctest.NewServer()
+		defer s.Close()
+
+		conn, err := s.ClientConn(grpc.WithChainStreamInterceptor(
+			logging.StreamClientInterceptor(logging.WithHandler(h)),
+		))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		echopb.RegisterEchoServiceServer(s, &holdServer{})
+		s.Serve()
+
+		client := echopb.NewEchoServiceClient(conn)
+		stream, err := client.ServerStreamingEcho(context.Background(), &echopb.ServerStreamingEchoRequest{Message: "engage"})
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		if _, err := stream.Recv(); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		// The stream is abandoned along with its connection.
+		conn.Close()
+
+		waitForRecords(t, h, 1)
+		assertClientRecord(t, h, "/echo.v1.EchoService/ServerStreamingEcho", codes.Canceled)
+	})
+
+	t.Run("logs a stream whose context is cancelled before it finishes", func(t *testing.T) {
+		closer, client, h := logtest.NewClient(t, &holdServer{})
+		defer closer()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stream, err := client.ServerStreamingEcho(ctx, &echopb.ServerStreamingEchoRequest{Message: "engage"})
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		if _, err := stream.Recv(); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		// The stream is abandoned without receiving any further messages.
+		cancel()
+
+		waitForRecords(t, h, 1)
+		assertClientRecord(t, h, "/echo.v1.EchoService/ServerStreamingEcho", codes.Canceled)
+
+		// Receiving the error of the cancelled stream does not log it again.
+		if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+			t.Fatalf("error codes are not equal: %s != %s", status.Code(err), codes.Canceled)
+		}
+		if records := h.Records(); len(records) != 1 {
+			t.Errorf("numbers of records are not equal: %d != %d", len(records), 1)
+		}
+	})
+}
@@ -1,22 +1,256 @@
 package logging
 
 import (
+	"context"
 	"io"
+	"net/http"
+	"time"
 
 	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc/codes"
+)
+
+// AWS CloudWatch specific attributes.
+const (
+	cloudWatchMetadataKey = "_aws"
+	cloudWatchSpanKey     = "spanId"
+	cloudWatchTraceKey    = "traceId"
 )
 
+// Metrics published in Embedded Metric Format for each request.
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+const (
+	// cloudWatchLatencyMetric is the duration of the request in milliseconds.
+	cloudWatchLatencyMetric = "latency"
+	// cloudWatchErrorMetric counts failed requests logged below the error
+	// level, typically those failing due to the client.
+	cloudWatchErrorMetric = "requestError"
+	// cloudWatchFaultMetric counts failed requests logged at the error level
+	// or above, typically those failing due to the server.
+	cloudWatchFaultMetric = "requestFault"
+)
+
+// DefaultMetricDimensions are the dimensions of the request metrics published
+// by a CloudWatchHandler when no others have been configured. Every request is
+// published by its method, which for gRPC is always POST, so RPCs are also
+// published by their kind and path. Since only RPCs carry a kind the path of
+// an HTTP request, whose distinct values would each create a new metric, is
+// not a dimension of its metrics.
+var DefaultMetricDimensions = [][]string{{MethodKey}, {KindKey, PathKey}}
+
 // CloudWatchHandler is a handler that formats log messages in a way that is
 // compatible with AWS CloudWatch.
+//
+// The SpanHandler and TraceHandler hooks may be used to correlate log entries
+// with AWS X-Ray. For requests carrying trace headers XRayTraceHandler and
+// SpanIDHandler provide these values.
+//
+// When MetricNamespace is set, the records written by RequestLogger and the
+// gRPC interceptors are formatted in Embedded Metric Format, publishing the
+// latency of each request, along with whether it resulted in an error or a
+// fault, as CloudWatch metrics. A failed request is a fault if it was logged
+// at the error level or above, as determined by WithStatusLevels and
+// WithCodeLevels, and is otherwise an error.
 type CloudWatchHandler struct {
 	*slog.JSONHandler
+	SpanHandler  AttrHandler
+	TraceHandler AttrHandler
+
+	// MetricNamespace is the namespace request metrics are published to. No
+	// metrics are published if it is empty.
+	MetricNamespace string
+
+	// MetricDimensions are the sets of attribute keys used as the dimensions
+	// of request metrics. The values of these attributes must be strings. A
+	// set is only used if every attribute in it is present on the record. If
+	// nil, DefaultMetricDimensions is used.
+	MetricDimensions [][]string
+
+	groups handlerGroups
 }
 
 // NewCloudWatchHandler returns a new CloudWatchHandler.
-func NewCloudWatchHandler(w io.Writer, level slog.Level) *CloudWatchHandler {
+func NewCloudWatchHandler(w io.Writer, level slog.Leveler) *CloudWatchHandler {
 	return &CloudWatchHandler{
 		JSONHandler: slog.HandlerOptions{
 			Level: level,
+
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && a.Key == slog.LevelKey {
+					a.Value = severityValue(a.Value)
+				}
+				return a
+			},
 		}.NewJSONHandler(w),
 	}
 }
+
+// Handle formats its argument Record as a JSON object on a single line.
+func (h *CloudWatchHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	request := make([]slog.Attr, 0)
+	isRequest := isRequestRecord(r)
+
+	// Separate out the attributes of a request record so that they remain at
+	// the top level, where they may be referenced as metric dimensions.
+	r.Attrs(func(a slog.Attr) {
+		if isRequestMarker(a) {
+			return
+		}
+		if isRequest && (isHTTPRequestAttr(a) || a.Key == CodeKey || a.Key == KindKey) {
+			request = append(request, a)
+			return
+		}
+		attrs = append(attrs, a)
+	})
+
+	// Nest the record attributes within any open groups, along with the
+	// attributes added to each group.
+	attrs = append(h.groups.nest(attrs), request...)
+
+	if h.MetricNamespace != "" && isRequest {
+		attrs = append(attrs, h.metricAttrs(r.Time, r.Level, request)...)
+	}
+
+	if h.SpanHandler != nil {
+		if span := h.SpanHandler(ctx); span != NilValue {
+			attrs = append(attrs, slog.Any(cloudWatchSpanKey, span))
+		}
+	}
+	if h.TraceHandler != nil {
+		if trace := h.TraceHandler(ctx); trace != NilValue {
+			attrs = append(attrs, slog.Any(cloudWatchTraceKey, trace))
+		}
+	}
+
+	// Create a new record with the attributes we want to keep.
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	record.AddAttrs(attrs...)
+
+	return h.JSONHandler.Handle(ctx, record)
+}
+
+// WithAttrs returns a new CloudWatchHandler whose attributes consists of h's
+// attributes followed by attrs.
+func (h *CloudWatchHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.groups = h.groups.withAttrs(attrs)
+	return &h2
+}
+
+// WithGroup returns a new CloudWatchHandler whose attributes consists of h's
+// attributes followed by a group with the given name.
+func (h *CloudWatchHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.groups = h.groups.withGroup(name)
+	return &h2
+}
+
+// metricAttrs returns the attributes publishing the metrics of a request in
+// Embedded Metric Format. Requests without a duration publish no metrics.
+func (h *CloudWatchHandler) metricAttrs(t time.Time, level slog.Level, request []slog.Attr) []slog.Attr {
+	var (
+		duration    time.Duration
+		hasDuration bool
+		failed      bool
+	)
+
+	present := make(map[string]bool, len(request))
+
+	for _, a := range request {
+		present[a.Key] = true
+
+		switch a.Key {
+		case DurationKey:
+			duration, hasDuration = durationValue(a.Value)
+		case StatusKey:
+			failed = a.Value.Kind() == slog.KindInt64 && a.Value.Int64() >= http.StatusBadRequest
+		case CodeKey:
+			code, ok := codeValues[a.Value.String()]
+			failed = ok && code != codes.OK
+		}
+	}
+
+	// The level of the record was determined by the configured level tables,
+	// which decide whether the failure was the fault of the server.
+	isFault := failed && level >= LevelError
+	isError := failed && !isFault
+
+	if !hasDuration {
+		return nil
+	}
+
+	dimensionSets := h.MetricDimensions
+	if dimensionSets == nil {
+		dimensionSets = DefaultMetricDimensions
+	}
+
+	// Only dimension sets whose attributes are all present may be used.
+	dimensions := make([][]string, 0, len(dimensionSets))
+	for _, set := range dimensionSets {
+		ok := true
+		for _, key := range set {
+			ok = ok && present[key]
+		}
+		if ok {
+			dimensions = append(dimensions, set)
+		}
+	}
+
+	metadata := emfMetadata{
+		Timestamp: t.UnixMilli(),
+		CloudWatchMetrics: []emfDirective{{
+			Namespace:  h.MetricNamespace,
+			Dimensions: dimensions,
+			Metrics: []emfMetric{
+				{Name: cloudWatchLatencyMetric, Unit: "Milliseconds"},
+				{Name: cloudWatchErrorMetric, Unit: "Count"},
+				{Name: cloudWatchFaultMetric, Unit: "Count"},
+			},
+		}},
+	}
+
+	return []slog.Attr{
+		slog.Any(cloudWatchMetadataKey, metadata),
+		slog.Float64(cloudWatchLatencyMetric, float64(duration)/float64(time.Millisecond)),
+		slog.Int(cloudWatchErrorMetric, boolCount(isError)),
+		slog.Int(cloudWatchFaultMetric, boolCount(isFault)),
+	}
+}
+
+// emfMetadata is the metadata object of an Embedded Metric Format entry.
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+// emfDirective instructs CloudWatch to extract metrics from an entry.
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+// emfMetric describes a single metric extracted from an entry.
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+// codeValues maps the string representation of each gRPC status code, as
+// written to the CodeKey attribute, back to the code.
+var codeValues = func() map[string]codes.Code {
+	m := make(map[string]codes.Code)
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		m[c.String()] = c
+	}
+	return m
+}()
+
+func boolCount(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,230 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kapetndev/connect/logging"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+)
+
+func newCloudWatchHandler(buf *bytes.Buffer) *logging.CloudWatchHandler {
+	h := logging.NewCloudWatchHandler(buf, logging.LevelTrace)
+	h.SpanHandler = logging.SpanIDHandler()
+	h.TraceHandler = logging.XRayTraceHandler()
+	h.MetricNamespace = "enterprise"
+	return h
+}
+
+func cloudWatchEntry(t *testing.T, h slog.Handler, buf *bytes.Buffer, level slog.Level, attrs ...any) map[string]interface{} {
+	buf.Reset()
+	slog.New(h).Log(traceCtx, level, "beam me up", attrs...)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to decode log entry: %s", err)
+	}
+
+	return entry
+}
+
+func TestCloudWatchHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("maps custom levels to readable strings", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		for level, expected := range map[slog.Level]string{
+			logging.LevelNotice:    "NOTICE",
+			logging.LevelEmergency: "EMERGENCY",
+			logging.LevelAlert:     "ALERT",
+			logging.LevelCritical:  "CRITICAL",
+		} {
+			entry := cloudWatchEntry(t, newCloudWatchHandler(buf), buf, level)
+			if entry["level"] != expected {
+				t.Errorf("levels are not equal: %v != %s", entry["level"], expected)
+			}
+		}
+	})
+
+	t.Run("adds the trace hooks", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := cloudWatchEntry(t, newCloudWatchHandler(buf), buf, logging.LevelInfo)
+
+		if entry["traceId"] != "1-4bf92f35-77b34da6a3ce929d0e0e4736" {
+			t.Errorf("traces are not equal: %v != %s", entry["traceId"], "1-4bf92f35-77b34da6a3ce929d0e0e4736")
+		}
+
+		if entry["spanId"] != spanID {
+			t.Errorf("spans are not equal: %v != %s", entry["spanId"], spanID)
+		}
+	})
+
+	t.Run("publishes request metrics in embedded metric format", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := cloudWatchEntry(t, newCloudWatchHandler(buf), buf, logging.LevelError,
+			logging.RequestMarker,
+			logging.MethodKey, "GET",
+			logging.PathKey, "/bridge",
+			logging.StatusKey, 503,
+			logging.DurationKey, 1500*time.Millisecond,
+		)
+
+		metadata := entry["_aws"].(map[string]interface{})
+		delete(metadata, "Timestamp")
+
+		assertJSON(t, "metric metadata", metadata, `{"CloudWatchMetrics":[{"Dimensions":[["method"]],"Metrics":[{"Name":"latency","Unit":"Milliseconds"},{"Name":"requestError","Unit":"Count"},{"Name":"requestFault","Unit":"Count"}],"Namespace":"enterprise"}]}`)
+
+		for key, expected := range map[string]float64{"latency": 1500, "requestError": 0, "requestFault": 1} {
+			if entry[key] != expected {
+				t.Errorf("%s metrics are not equal: %v != %v", key, entry[key], expected)
+			}
+		}
+	})
+
+	t.Run("classifies gRPC status codes as errors or faults", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := cloudWatchEntry(t, newCloudWatchHandler(buf), buf, logging.LevelWarning,
+			logging.RequestMarker,
+			logging.MethodKey, "Engage",
+			logging.PathKey, "/starfleet.Bridge/Engage",
+			logging.CodeKey, "NotFound",
+			logging.DurationKey, time.Millisecond,
+		)
+
+		if entry["requestError"] != float64(1) || entry["requestFault"] != float64(0) {
+			t.Errorf("metrics are not equal: %v, %v != 1, 0", entry["requestError"], entry["requestFault"])
+		}
+	})
+
+	t.Run("does not collide with the error of an RPC", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		interceptor := logging.UnaryServerInterceptor(logging.WithHandler(newCloudWatchHandler(buf)))
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/echo.v1.EchoService/Echo"}
+		_, _ = interceptor(context.Background(), &echopb.EchoRequest{}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Internal, "boom")
+		})
+
+		if n := bytes.Count(buf.Bytes(), []byte(`"error":`)); n != 1 {
+			t.Errorf("entry does not have a single error key: %s", buf.Bytes())
+		}
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		if entry["error"] != "rpc error: code = Internal desc = boom" {
+			t.Errorf("errors are not equal: %v != %s", entry["error"], "rpc error: code = Internal desc = boom")
+		}
+		if entry["requestError"] != float64(0) || entry["requestFault"] != float64(1) {
+			t.Errorf("metrics are not equal: %v, %v != 0, 1", entry["requestError"], entry["requestFault"])
+		}
+	})
+
+	t.Run("publishes the metrics of an RPC by its path", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		interceptor := logging.UnaryServerInterceptor(logging.WithHandler(newCloudWatchHandler(buf)))
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/echo.v1.EchoService/Echo"}
+		_, _ = interceptor(context.Background(), &echopb.EchoRequest{}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &echopb.EchoResponse{}, nil
+		})
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		metadata := entry["_aws"].(map[string]interface{})
+		assertJSON(t, "dimensions", metadata["CloudWatchMetrics"].([]interface{})[0].(map[string]interface{})["Dimensions"], `[["method"],["kind","path"]]`)
+
+		if entry[logging.PathKey] != "/echo.v1.EchoService/Echo" {
+			t.Errorf("paths are not equal: %v != %s", entry[logging.PathKey], "/echo.v1.EchoService/Echo")
+		}
+	})
+
+	t.Run("classifies failures using the configured levels", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		interceptor := logging.UnaryServerInterceptor(
+			logging.WithHandler(newCloudWatchHandler(buf)),
+			logging.WithCodeLevels(func(code codes.Code) slog.Level {
+				if code == codes.NotFound {
+					return logging.LevelError
+				}
+				return logging.DefaultCodeLevel(code)
+			}),
+		)
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/echo.v1.EchoService/Echo"}
+		_, _ = interceptor(context.Background(), &echopb.EchoRequest{}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.NotFound, "no such ship")
+		})
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		if entry["requestError"] != float64(0) || entry["requestFault"] != float64(1) {
+			t.Errorf("metrics are not equal: %v, %v != 0, 1", entry["requestError"], entry["requestFault"])
+		}
+	})
+
+	t.Run("omits dimension sets with missing attributes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := cloudWatchEntry(t, newCloudWatchHandler(buf), buf, logging.LevelInfo,
+			logging.RequestMarker,
+			logging.PathKey, "/bridge",
+			logging.DurationKey, time.Millisecond,
+		)
+
+		metadata := entry["_aws"].(map[string]interface{})
+		assertJSON(t, "dimensions", metadata["CloudWatchMetrics"].([]interface{})[0].(map[string]interface{})["Dimensions"], `[]`)
+	})
+
+	t.Run("does not publish metrics for records without a duration", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := cloudWatchEntry(t, newCloudWatchHandler(buf), buf, logging.LevelInfo, logging.RequestMarker, logging.StatusKey, 200)
+
+		if _, ok := entry["_aws"]; ok {
+			t.Error("metric metadata was written")
+		}
+	})
+
+	t.Run("does not publish metrics for records other than those of requests", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newCloudWatchHandler(buf).WithGroup("crew")
+
+		entry := cloudWatchEntry(t, h, buf, logging.LevelInfo,
+			logging.StatusKey, "done",
+			logging.DurationKey, time.Millisecond,
+		)
+
+		if _, ok := entry["_aws"]; ok {
+			t.Error("metric metadata was written")
+		}
+
+		assertJSON(t, "groups", entry["crew"], `{"duration":1000000,"status":"done"}`)
+	})
+
+	t.Run("keeps request attributes at the top level within a group", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newCloudWatchHandler(buf).WithGroup("crew")
+
+		entry := cloudWatchEntry(t, h, buf, logging.LevelInfo,
+			logging.RequestMarker,
+			logging.MethodKey, "GET",
+			logging.PathKey, "/bridge",
+			logging.DurationKey, time.Millisecond,
+			"captain", "picard",
+		)
+
+		if entry["method"] != "GET" {
+			t.Errorf("methods are not equal: %v != %s", entry["method"], "GET")
+		}
+
+		if _, ok := entry["_aws"]; !ok {
+			t.Error("metric metadata was not written")
+		}
+
+		assertJSON(t, "groups", entry["crew"], `{"captain":"picard"}`)
+	})
+}
@@ -0,0 +1,393 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// ANSI escape sequences used to colour console output.
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiFaint   = "\x1b[2m"
+	ansiRed     = "\x1b[31m"
+	ansiGreen   = "\x1b[32m"
+	ansiYellow  = "\x1b[33m"
+	ansiBlue    = "\x1b[34m"
+	ansiMagenta = "\x1b[35m"
+	ansiCyan    = "\x1b[36m"
+	ansiGray    = "\x1b[90m"
+)
+
+// Widths of the aligned columns written by a ConsoleHandler. Values longer
+// than a column are written in full, pushing back the columns following it.
+const (
+	consoleLevelWidth    = len("EMERGENCY")
+	consoleDurationWidth = 10
+	consoleStatusWidth   = 8
+	consoleMethodWidth   = len("OPTIONS")
+)
+
+// consoleTimeFormat is the format of the time written by a ConsoleHandler.
+const consoleTimeFormat = "15:04:05.000"
+
+// ConsoleOption configures a ConsoleHandler.
+type ConsoleOption func(*ConsoleHandler)
+
+// WithConsoleColor forces colour on or off, overriding the automatic
+// detection of a terminal.
+func WithConsoleColor(enabled bool) ConsoleOption {
+	return func(h *ConsoleHandler) {
+		h.color = enabled
+	}
+}
+
+// ConsoleHandler is a handler that formats log messages to be read by a
+// developer on a console, rather than ingested by a log management service.
+//
+// Each entry is written on a single line, beginning with the time and the
+// level. Entries logged by the middleware and interceptors are followed by
+// their duration, status, method and path, aligned in columns, then by any
+// other attributes as key=value pairs. Request and response payloads are
+// pretty-printed on the lines that follow.
+//
+// Levels are coloured by severity when writing to a terminal, unless the
+// NO_COLOR environment variable is set.
+type ConsoleHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	level slog.Leveler
+	color bool
+
+	// groups is kept apart from the record so that the columns are only
+	// ever read from its top level.
+	groups handlerGroups
+}
+
+// NewConsoleHandler returns a new ConsoleHandler.
+func NewConsoleHandler(w io.Writer, level slog.Leveler, opts ...ConsoleOption) *ConsoleHandler {
+	h := &ConsoleHandler{
+		w:     w,
+		mu:    &sync.Mutex{},
+		level: level,
+		color: isTerminal(w) && os.Getenv("NO_COLOR") == "",
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Enabled reports whether the handler handles records at the given level. The
+// handler ignores records whose level is lower.
+func (h *ConsoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return enabled(h.level, level)
+}
+
+// Handle formats its argument Record on a single line, followed by any
+// payloads.
+func (h *ConsoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var (
+		buf      bytes.Buffer
+		columns  consoleColumns
+		attrs    = make([]slog.Attr, 0, r.NumAttrs())
+		payloads []slog.Attr
+	)
+
+	// Only the entries logged by the middleware and interceptors are written
+	// with columns.
+	columns.enabled = isRequestRecord(r)
+
+	// Separate out the attributes written as columns and payloads.
+	r.Attrs(func(a slog.Attr) {
+		if isRequestMarker(a) {
+			return
+		}
+		if columns.enabled && columns.set(a) {
+			return
+		}
+		if a.Key == RequestKey || a.Key == ResponseKey {
+			payloads = append(payloads, a)
+			return
+		}
+		attrs = append(attrs, a)
+	})
+
+	if !r.Time.IsZero() {
+		h.writeColor(&buf, ansiFaint, r.Time.Format(consoleTimeFormat))
+		buf.WriteByte(' ')
+	}
+
+	level := severityValue(slog.AnyValue(r.Level)).String()
+	h.writeColor(&buf, levelColor(r.Level), fmt.Sprintf("%-*s", consoleLevelWidth, level))
+
+	if columns.enabled {
+		buf.WriteByte(' ')
+		columns.write(&buf, h)
+	}
+
+	if r.Message != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(r.Message)
+	}
+
+	for _, a := range h.groups.nest(attrs) {
+		h.writeAttr(&buf, "", a)
+	}
+
+	buf.WriteByte('\n')
+
+	for _, a := range payloads {
+		h.writePayload(&buf, a)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+// WithAttrs returns a new ConsoleHandler whose attributes consists of h's
+// attributes followed by attrs.
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.groups = h.groups.withAttrs(attrs)
+	return &h2
+}
+
+// WithGroup returns a new ConsoleHandler whose attributes consists of h's
+// attributes followed by a group with the given name.
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.groups = h.groups.withGroup(name)
+	return &h2
+}
+
+// writeColor writes s to buf, wrapped in the given colour if enabled.
+func (h *ConsoleHandler) writeColor(buf *bytes.Buffer, color, s string) {
+	if !h.color || color == "" {
+		buf.WriteString(s)
+		return
+	}
+
+	buf.WriteString(color)
+	buf.WriteString(s)
+	buf.WriteString(ansiReset)
+}
+
+// writeAttr writes a as a key=value pair. The attributes of a group are
+// written individually with their keys qualified by the group name.
+func (h *ConsoleHandler) writeAttr(buf *bytes.Buffer, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	key := a.Key
+	if prefix != "" {
+		key = prefix + "." + a.Key
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			h.writeAttr(buf, key, ga)
+		}
+		return
+	}
+
+	buf.WriteByte(' ')
+	h.writeColor(buf, ansiFaint, key+"=")
+	buf.WriteString(consoleValue(a.Value))
+}
+
+// writePayload writes the payload held by a, pretty-printed and indented on
+// the lines following the entry.
+func (h *ConsoleHandler) writePayload(buf *bytes.Buffer, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	var b []byte
+	switch a.Value.Kind() {
+	case slog.KindGroup:
+		m := make(map[string]any, len(a.Value.Group()))
+		for _, ga := range a.Value.Group() {
+			m[ga.Key] = ga.Value.Resolve().Any()
+		}
+		b, _ = json.Marshal(m)
+	case slog.KindAny:
+		b, _ = json.Marshal(a.Value.Any())
+	default:
+		b = []byte(a.Value.String())
+	}
+
+	const indent = "    "
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, indent, "  "); err != nil {
+		out.Reset()
+		out.Write(b)
+	}
+
+	buf.WriteString(indent)
+	h.writeColor(buf, ansiFaint, a.Key+":")
+	buf.WriteByte('\n')
+	buf.WriteString(indent)
+	buf.Write(out.Bytes())
+	buf.WriteByte('\n')
+}
+
+// consoleColumns holds the attributes of a request entry written as aligned
+// columns.
+type consoleColumns struct {
+	enabled bool
+
+	duration, status, method, path slog.Value
+	hasDuration, hasStatus         bool
+}
+
+// set records a if it is written as a column, reporting whether it was.
+func (c *consoleColumns) set(a slog.Attr) bool {
+	switch a.Key {
+	case DurationKey:
+		c.duration, c.hasDuration = a.Value, true
+	case StatusKey, CodeKey:
+		c.status, c.hasStatus = a.Value, true
+	case MethodKey:
+		c.method = a.Value
+	case PathKey:
+		c.path = a.Value
+	default:
+		return false
+	}
+	return true
+}
+
+// write writes the columns to buf.
+func (c *consoleColumns) write(buf *bytes.Buffer, h *ConsoleHandler) {
+	duration := ""
+	if d, ok := durationValue(c.duration); ok && c.hasDuration {
+		duration = formatConsoleDuration(d)
+	}
+	fmt.Fprintf(buf, "%*s ", consoleDurationWidth, duration)
+
+	status := ""
+	if c.hasStatus {
+		status = c.status.String()
+	}
+	h.writeColor(buf, statusColor(c.status), fmt.Sprintf("%-*s", consoleStatusWidth, status))
+
+	fmt.Fprintf(buf, " %-*s ", consoleMethodWidth, valueString(c.method))
+	buf.WriteString(valueString(c.path))
+}
+
+// valueString returns the string representation of v, or an empty string if
+// v is unset.
+func valueString(v slog.Value) string {
+	if v.Kind() == slog.KindAny && v.Any() == nil {
+		return ""
+	}
+	return v.String()
+}
+
+// formatConsoleDuration formats d rounded to a precision suited to its
+// magnitude, such as 1.5s, 12.34ms or 250µs.
+func formatConsoleDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+// consoleValue formats v as the value of a key=value pair. Strings are quoted
+// if they would otherwise be ambiguous.
+func consoleValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindString:
+		s := v.String()
+		if s == "" || strings.ContainsAny(s, " =\"\t\n") {
+			return strconv.Quote(s)
+		}
+		return s
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case json.Marshaler:
+			if b, err := json.Marshal(x); err == nil {
+				return string(b)
+			}
+		case error:
+			return strconv.Quote(x.Error())
+		}
+	}
+	return v.String()
+}
+
+// levelColor returns the colour in which a level is written.
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= LevelEmergency:
+		return ansiBold + ansiMagenta
+	case level >= LevelError:
+		return ansiRed
+	case level >= LevelWarning:
+		return ansiYellow
+	case level >= LevelNotice:
+		return ansiCyan
+	case level >= LevelInfo:
+		return ansiBlue
+	default:
+		return ansiGray
+	}
+}
+
+// statusColor returns the colour in which an HTTP status or gRPC code is
+// written.
+func statusColor(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindInt64:
+		switch status := v.Int64(); {
+		case status >= 500:
+			return ansiRed
+		case status >= 400:
+			return ansiYellow
+		default:
+			return ansiGreen
+		}
+	case slog.KindString:
+		if v.String() == "OK" {
+			return ansiGreen
+		}
+		return ansiRed
+	default:
+		return ""
+	}
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
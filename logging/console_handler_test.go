@@ -0,0 +1,103 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+// durationPattern matches the duration column of a console entry.
+var durationPattern = regexp.MustCompile(`^ +[0-9.]+[µnm]?s `)
+
+func TestConsoleHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes the level names of custom levels", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logging.New(logging.NewConsoleHandler(buf, logging.LevelTrace))
+		logger.Trace(context.Background(), "scanning")
+		logger.Notice(context.Background(), "hailing")
+		logger.Critical(context.Background(), "warp core breach")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		for i, want := range []string{"TRACE     scanning", "NOTICE    hailing", "CRITICAL  warp core breach"} {
+			if got := lines[i][len("15:04:05.000 "):]; got != want {
+				t.Errorf("lines are not equal: %q != %q", got, want)
+			}
+		}
+	})
+
+	t.Run("writes attributes as key value pairs", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logging.New(logging.NewConsoleHandler(buf, logging.LevelInfo)).With("captain", "picard").WithGroup("ship")
+		logger.Info(context.Background(), "engage", "name", "USS Enterprise", "warp", 9)
+
+		want := `INFO      engage captain=picard ship.name="USS Enterprise" ship.warp=9` + "\n"
+		if got := buf.String()[len("15:04:05.000 "):]; got != want {
+			t.Errorf("lines are not equal: %q != %q", got, want)
+		}
+	})
+
+	t.Run("aligns the columns of request entries and pretty-prints payloads", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		mw := logging.RequestLogger(logging.WithHandler(logging.NewConsoleHandler(buf, logging.LevelInfo)))
+		handler := mw(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"captain":"picard"}`))
+		})
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bridge", nil))
+
+		lines := strings.Split(buf.String(), "\n")
+		entry := lines[0][len("15:04:05.000 WARNING   "):]
+
+		loc := durationPattern.FindStringIndex(entry)
+		if loc == nil || utf8.RuneCountInString(entry[:loc[1]]) != 11 {
+			t.Fatalf("duration column was not aligned: %q", entry)
+		}
+		if !strings.HasPrefix(entry[loc[1]:], "404      GET     /bridge ") {
+			t.Errorf("columns were not aligned: %q", entry[loc[1]:])
+		}
+
+		want := "    jsonPayload:\n    {\n      \"captain\": \"picard\"\n    }"
+		if got := strings.Join(lines[1:5], "\n"); got != want {
+			t.Errorf("payloads are not equal: %q != %q", got, want)
+		}
+	})
+
+	t.Run("colours the level when colour is enabled", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logging.New(logging.NewConsoleHandler(buf, logging.LevelInfo, logging.WithConsoleColor(true)))
+		logger.Error(context.Background(), "warp core breach")
+
+		if !strings.Contains(buf.String(), "\x1b[31mERROR    \x1b[0m") {
+			t.Errorf("level was not coloured: %q", buf.String())
+		}
+	})
+
+	t.Run("does not colour output written to a writer that is not a terminal", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logging.New(logging.NewConsoleHandler(buf, logging.LevelInfo))
+		logger.Error(context.Background(), "warp core breach")
+
+		if strings.Contains(buf.String(), "\x1b[") {
+			t.Errorf("output was coloured: %q", buf.String())
+		}
+	})
+
+	t.Run("ignores records below the level", func(t *testing.T) {
+		h := logging.NewConsoleHandler(&bytes.Buffer{}, slog.LevelWarn)
+		if h.Enabled(context.Background(), logging.LevelInfo) {
+			t.Error("handler was enabled at the info level")
+		}
+	})
+}
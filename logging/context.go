@@ -1,21 +1,89 @@
 package logging
 
-import "context"
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/exp/slog"
+)
 
 type loggerContextKey struct{}
 
 // FromContext returns the LeveledLogger value stored in ctx, if any. If no
 // LeveledLogger can be found then a default logger is returned.
 func FromContext(ctx context.Context) *LeveledLogger {
-	logger, ok := ctx.Value(loggerContextKey{}).(*LeveledLogger)
-	if !ok {
+	switch v := ctx.Value(loggerContextKey{}).(type) {
+	case *LeveledLogger:
+		return v
+	case *requestScope:
+		return v.logger()
+	default:
 		return Default()
 	}
-
-	return logger
 }
 
 // NewContext returns a new Context that carries a LeveledLogger.
 func NewContext(parent context.Context, logger *LeveledLogger) context.Context {
 	return context.WithValue(parent, loggerContextKey{}, logger)
 }
+
+// AddAttrs adds attributes to the request scoped logger stored in ctx by
+// RequestLogger or the server interceptors. The attributes are included in
+// every entry subsequently logged by the logger returned from FromContext, as
+// well as in the entry logged once the request completes. The arguments are
+// interpreted as by slog.Logger.With.
+//
+// AddAttrs has no effect if ctx does not carry a request scoped logger, such
+// as when the logger has been replaced using NewContext.
+func AddAttrs(ctx context.Context, args ...any) {
+	if scope, ok := ctx.Value(loggerContextKey{}).(*requestScope); ok {
+		scope.add(args...)
+	}
+}
+
+// requestScope holds the logger scoped to a single request, along with the
+// attributes added to it by AddAttrs.
+type requestScope struct {
+	mu  sync.Mutex
+	l   *LeveledLogger
+	all []slog.Attr
+}
+
+func newRequestScope(logger *LeveledLogger) *requestScope {
+	return &requestScope{l: logger}
+}
+
+// newRequestContext returns a new Context that carries the request scope.
+func newRequestContext(parent context.Context, scope *requestScope) context.Context {
+	return context.WithValue(parent, loggerContextKey{}, scope)
+}
+
+func (s *requestScope) add(args ...any) {
+	// Convert the arguments to attributes in the same way as the logger.
+	var r slog.Record
+	r.Add(args...)
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) {
+		attrs = append(attrs, a)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.l = s.l.WithAttrs(attrs...)
+	s.all = append(s.all, attrs...)
+}
+
+func (s *requestScope) logger() *LeveledLogger {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.l
+}
+
+// attrs returns the attributes added to the request scope.
+func (s *requestScope) attrs() []slog.Attr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.all[:len(s.all):len(s.all)]
+}
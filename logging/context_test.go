@@ -0,0 +1,114 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc"
+
+	"github.com/kapetndev/connect/logging"
+	"github.com/kapetndev/connect/requestid"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+)
+
+func TestLeveledLogger_With(t *testing.T) {
+	t.Parallel()
+
+	t.Run("includes the attributes in each entry", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logging.New(slog.NewJSONHandler(buf)).With("captain", "picard")
+		logger.Info(context.Background(), "engage")
+
+		entries := decodeLogEntries(t, buf.Bytes())
+		assertJSON(t, "captain", entries[0]["captain"], `"picard"`)
+	})
+
+	t.Run("qualifies subsequent attributes by the group", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logging.New(slog.NewJSONHandler(buf)).WithGroup("ship").With("name", "enterprise")
+		logger.Info(context.Background(), "engage", "registry", "NCC-1701-D")
+
+		entries := decodeLogEntries(t, buf.Bytes())
+		assertJSON(t, "ship", entries[0]["ship"], `{"name":"enterprise","registry":"NCC-1701-D"}`)
+	})
+}
+
+func TestAddAttrs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("does nothing without a request scoped logger", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		ctx := logging.NewContext(context.Background(), logging.New(slog.NewJSONHandler(buf)))
+
+		logging.AddAttrs(ctx, "captain", "picard")
+		logging.FromContext(ctx).Info(ctx, "engage")
+
+		entries := decodeLogEntries(t, buf.Bytes())
+		if _, ok := entries[0]["captain"]; ok {
+			t.Errorf("unexpected attribute: %v", entries[0]["captain"])
+		}
+	})
+
+	t.Run("adds the attributes to the RequestLogger entries", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		mw := logging.RequestLogger(logging.WithHandler(slog.NewJSONHandler(buf)))
+		mw(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			logging.FromContext(ctx).Info(ctx, "hailing")
+			logging.AddAttrs(ctx, "captain", "picard")
+			logging.FromContext(ctx).Info(ctx, "engage")
+		})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bridge", nil))
+
+		entries := decodeLogEntries(t, buf.Bytes())
+		assertJSON(t, "messages", messages(t, buf.Bytes()), `["hailing","engage",""]`)
+
+		if _, ok := entries[0]["captain"]; ok {
+			t.Errorf("unexpected attribute: %v", entries[0]["captain"])
+		}
+		assertJSON(t, "captain", entries[1]["captain"], `"picard"`)
+		assertJSON(t, "captain", entries[2]["captain"], `"picard"`)
+	})
+
+	t.Run("adds the attributes to the interceptor entries", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		interceptor := logging.UnaryServerInterceptor(logging.WithHandler(slog.NewJSONHandler(buf)))
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/echo.v1.EchoService/Echo"}
+		_, _ = interceptor(context.Background(), &echopb.EchoRequest{}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			logging.AddAttrs(ctx, slog.Group("crew", slog.String("captain", "picard")))
+			return &echopb.EchoResponse{}, nil
+		})
+
+		entries := decodeLogEntries(t, buf.Bytes())
+		assertJSON(t, "crew", entries[0]["crew"], `{"captain":"picard"}`)
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("adds the request ID to the RequestLogger entries", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		mw := logging.RequestLogger(logging.WithHandler(slog.NewJSONHandler(buf)))
+		handler := requestid.Handler()(mw(func(w http.ResponseWriter, r *http.Request) {
+			logging.FromContext(r.Context()).Info(r.Context(), "engage")
+		}))
+
+		r := httptest.NewRequest(http.MethodGet, "/bridge", nil)
+		r.Header.Set(requestid.Header, "NCC-1701-D")
+		handler(httptest.NewRecorder(), r)
+
+		entries := decodeLogEntries(t, buf.Bytes())
+		assertJSON(t, "messages", messages(t, buf.Bytes()), `["engage",""]`)
+		assertJSON(t, "requestId", entries[0][logging.RequestIDKey], `"NCC-1701-D"`)
+		assertJSON(t, "requestId", entries[1][logging.RequestIDKey], `"NCC-1701-D"`)
+	})
+}
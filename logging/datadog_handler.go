@@ -0,0 +1,296 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// Datadog specific attributes.
+// https://docs.datadoghq.com/logs/log_configuration/attributes_naming_convention/
+const (
+	datadogDurationKey  = "duration"
+	datadogEnvKey       = "env"
+	datadogErrorKey     = "error"
+	datadogMessageKey   = "message"
+	datadogMetadataKey  = "dd"
+	datadogServiceKey   = "service"
+	datadogSpanKey      = "span_id"
+	datadogStatusKey    = "status"
+	datadogTimestampKey = "timestamp"
+	datadogTraceKey     = "trace_id"
+	datadogVersionKey   = "version"
+)
+
+// DatadogOption configures a DatadogHandler.
+type DatadogOption func(*DatadogHandler)
+
+// WithDatadogService sets the service written to each log entry, the unified
+// service tag used to correlate logs with traces and metrics.
+// https://docs.datadoghq.com/getting_started/tagging/unified_service_tagging/
+func WithDatadogService(service string) DatadogOption {
+	return func(h *DatadogHandler) {
+		h.service = service
+	}
+}
+
+// WithDatadogEnv sets the environment written to each log entry, the unified
+// env tag used to correlate logs with traces and metrics.
+func WithDatadogEnv(env string) DatadogOption {
+	return func(h *DatadogHandler) {
+		h.env = env
+	}
+}
+
+// WithDatadogVersion sets the version written to each log entry, the unified
+// version tag used to correlate logs with traces and metrics.
+func WithDatadogVersion(version string) DatadogOption {
+	return func(h *DatadogHandler) {
+		h.version = version
+	}
+}
+
+// DatadogHandler is a handler that formats log messages in a way that is
+// compatible with Datadog log management.
+//
+// The SpanHandler and TraceHandler hooks correlate log entries with Datadog
+// APM. They default to DatadogSpanIDHandler and DatadogTraceIDHandler, which
+// provide the IDs in the decimal format expected by Datadog.
+type DatadogHandler struct {
+	handler      slog.Handler
+	SpanHandler  AttrHandler
+	TraceHandler AttrHandler
+
+	service string
+	env     string
+	version string
+
+	// Open groups apply to the attributes of the record only, never to the
+	// standard Datadog attributes.
+	groups handlerGroups
+	fields []slog.Attr
+}
+
+// NewDatadogHandler returns a new DatadogHandler.
+func NewDatadogHandler(w io.Writer, level slog.Leveler, opts ...DatadogOption) *DatadogHandler {
+	h := &DatadogHandler{
+		handler: slog.HandlerOptions{
+			Level: level,
+
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				// Only the built-in attributes are renamed, and these are never
+				// within a group.
+				if len(groups) > 0 {
+					return a
+				}
+
+				switch a.Key {
+				case slog.TimeKey:
+					a.Key = datadogTimestampKey
+				case slog.LevelKey:
+					a.Key = datadogStatusKey
+					a.Value = slog.StringValue(strings.ToLower(severityValue(a.Value).String()))
+				case slog.MessageKey:
+					a.Key = datadogMessageKey
+				}
+
+				return a
+			},
+		}.NewJSONHandler(w),
+		SpanHandler:  DatadogSpanIDHandler(),
+		TraceHandler: DatadogTraceIDHandler(),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Enabled reports whether the handler handles records at the given level. The
+// handler ignores records whose level is lower.
+func (h *DatadogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle formats its argument Record as a JSON object on a single line.
+func (h *DatadogHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	fields := append(make([]slog.Attr, 0, len(h.fields)), h.fields...)
+	request := isRequestRecord(r)
+
+	// Separate out the attributes with an equivalent standard attribute.
+	r.Attrs(func(a slog.Attr) {
+		if isRequestMarker(a) {
+			return
+		}
+		if isDatadogAttr(a, request) {
+			fields = append(fields, a)
+			return
+		}
+		attrs = append(attrs, a)
+	})
+
+	// Nest the record attributes within any open groups, along with the
+	// attributes added to each group.
+	attrs = append(h.groups.nest(attrs), datadogFields(fields)...)
+
+	// The service is a reserved attribute, but is also written alongside the
+	// trace IDs as done by the Datadog tracing libraries.
+	if h.service != "" {
+		attrs = append(attrs, slog.String(datadogServiceKey, h.service))
+	}
+
+	metadata := make([]slog.Attr, 0, 5)
+	if h.service != "" {
+		metadata = append(metadata, slog.String(datadogServiceKey, h.service))
+	}
+	if h.env != "" {
+		metadata = append(metadata, slog.String(datadogEnvKey, h.env))
+	}
+	if h.version != "" {
+		metadata = append(metadata, slog.String(datadogVersionKey, h.version))
+	}
+	if h.TraceHandler != nil {
+		if trace := h.TraceHandler(ctx); trace != NilValue {
+			metadata = append(metadata, slog.Any(datadogTraceKey, trace))
+		}
+	}
+	if h.SpanHandler != nil {
+		if span := h.SpanHandler(ctx); span != NilValue {
+			metadata = append(metadata, slog.Any(datadogSpanKey, span))
+		}
+	}
+	attrs = appendGroup(attrs, datadogMetadataKey, metadata)
+
+	// Create a new record with the attributes we want to keep.
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	record.AddAttrs(attrs...)
+
+	return h.handler.Handle(ctx, record)
+}
+
+// WithAttrs returns a new DatadogHandler whose attributes consists of h's
+// attributes followed by attrs. Errors are always written to the standard
+// error attribute, even when added within a group.
+func (h *DatadogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := h.clone()
+
+	regular := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if isDatadogAttr(a, false) {
+			h2.fields = append(h2.fields, a)
+			continue
+		}
+		regular = append(regular, a)
+	}
+
+	h2.groups = h2.groups.withAttrs(regular)
+	return h2
+}
+
+// WithGroup returns a new DatadogHandler whose attributes consists of h's
+// attributes followed by a group with the given name.
+func (h *DatadogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := h.clone()
+	h2.groups = h2.groups.withGroup(name)
+	return h2
+}
+
+// clone returns a copy of h for WithAttrs and WithGroup.
+func (h *DatadogHandler) clone() *DatadogHandler {
+	h2 := *h
+	h2.fields = h.fields[:len(h.fields):len(h.fields)]
+	return &h2
+}
+
+// isDatadogAttr reports whether a has an equivalent Datadog standard
+// attribute. Only errors are considered unless a is an attribute of a request
+// record, since an application may log attributes of the same name for other
+// purposes.
+func isDatadogAttr(a slog.Attr, request bool) bool {
+	return a.Key == datadogErrorKey || (request && isHTTPRequestAttr(a))
+}
+
+// datadogFields converts attributes to their equivalent Datadog standard
+// attributes.
+// https://docs.datadoghq.com/standard-attributes/
+func datadogFields(attrs []slog.Attr) []slog.Attr {
+	var (
+		http, urlDetails, errs              []slog.Attr
+		network, client, destination, extra []slog.Attr
+	)
+
+	for _, a := range attrs {
+		switch a.Key {
+		case MethodKey:
+			http = append(http, slog.String("method", a.Value.String()))
+		case StatusKey:
+			http = append(http, slog.Any("status_code", a.Value))
+		case URLKey:
+			http = append(http, slog.String("url", a.Value.String()))
+		case PathKey:
+			urlDetails = append(urlDetails, slog.String("path", a.Value.String()))
+		case RefererKey:
+			http = append(http, slog.String("referer", a.Value.String()))
+		case UserAgentKey:
+			http = append(http, slog.String("useragent", a.Value.String()))
+		case ProtocolKey:
+			http = append(http, slog.String("version", strings.TrimPrefix(a.Value.String(), "HTTP/")))
+		case RequestSizeKey:
+			network = append(network, slog.Any("bytes_read", a.Value))
+		case ResponseSizeKey:
+			network = append(network, slog.Any("bytes_written", a.Value))
+		case RemoteIPKey:
+			client = append(client, hostPortAttrs(a.Value.String())...)
+		case ServerIPKey:
+			destination = append(destination, hostPortAttrs(a.Value.String())...)
+		case DurationKey:
+			if d, ok := durationValue(a.Value); ok {
+				extra = append(extra, slog.Int64(datadogDurationKey, d.Nanoseconds()))
+			}
+		case datadogErrorKey:
+			errs = append(errs, slog.String("message", a.Value.String()))
+		}
+	}
+
+	http = appendGroup(http, "url_details", urlDetails)
+	network = appendGroup(network, "client", client)
+	network = appendGroup(network, "destination", destination)
+
+	fields := make([]slog.Attr, 0, 4+len(extra))
+	fields = appendGroup(fields, "http", http)
+	fields = appendGroup(fields, "network", network)
+	fields = appendGroup(fields, "error", errs)
+
+	return append(fields, extra...)
+}
+
+// hostPortAttrs splits an address into ip and port attributes. Addresses
+// without a port are written as the ip.
+func hostPortAttrs(addr string) []slog.Attr {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return []slog.Attr{slog.String("ip", addr)}
+	}
+
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return []slog.Attr{slog.String("ip", host)}
+	}
+
+	return []slog.Attr{slog.String("ip", host), slog.Int("port", port)}
+}
@@ -0,0 +1,100 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+func TestDatadogHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("writes the level as the status", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		slog.New(logging.NewDatadogHandler(buf, logging.LevelTrace)).Log(traceCtx, logging.LevelCritical, "beam me up")
+
+		if !bytes.Contains(buf.Bytes(), []byte(`"status":"critical"`)) {
+			t.Errorf("status was not written: %s", buf.Bytes())
+		}
+	})
+
+	t.Run("writes the unified service tags and trace IDs", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := logging.NewDatadogHandler(buf, logging.LevelTrace,
+			logging.WithDatadogService("bridge"),
+			logging.WithDatadogEnv("production"),
+			logging.WithDatadogVersion("1.7.0"),
+		)
+
+		entry := logEntry(t, h, buf)
+
+		if entry["service"] != "bridge" {
+			t.Errorf("services are not equal: %v != %s", entry["service"], "bridge")
+		}
+
+		assertJSON(t, "dd objects", entry["dd"], `{"env":"production","service":"bridge","span_id":"67667974448284343","trace_id":"11803532876627986230","version":"1.7.0"}`)
+	})
+
+	t.Run("omits a malformed trace ID", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		ctx := logging.NewTraceContext(context.Background(), logging.TraceContext{TraceID: "abc", SpanID: spanID})
+		logging.New(logging.NewDatadogHandler(buf, logging.LevelTrace)).Info(ctx, "beam me up")
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		assertJSON(t, "dd objects", entry["dd"], `{"span_id":"67667974448284343"}`)
+	})
+
+	t.Run("maps request attributes to standard attributes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := logEntry(t, logging.NewDatadogHandler(buf, logging.LevelTrace), buf,
+			logging.RequestMarker,
+			logging.MethodKey, "GET",
+			logging.PathKey, "/bridge",
+			logging.StatusKey, 200,
+			logging.RemoteIPKey, "192.0.2.1:1234",
+			logging.DurationKey, 1500*time.Millisecond,
+		)
+
+		if entry["status"] != "info" {
+			t.Errorf("statuses are not equal: %v != %s", entry["status"], "info")
+		}
+
+		if entry["duration"] != float64(1500000000) {
+			t.Errorf("durations are not equal: %v != %d", entry["duration"], 1500000000)
+		}
+
+		assertJSON(t, "http objects", entry["http"], `{"method":"GET","status_code":200,"url_details":{"path":"/bridge"}}`)
+		assertJSON(t, "network objects", entry["network"], `{"client":{"ip":"192.0.2.1","port":1234}}`)
+	})
+
+	t.Run("keeps standard attributes at the top level within a group", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := logging.NewDatadogHandler(buf, logging.LevelTrace).WithGroup("crew")
+
+		entry := logEntry(t, h, buf, logging.RequestMarker, logging.MethodKey, "GET", "captain", "picard")
+
+		assertJSON(t, "http objects", entry["http"], `{"method":"GET"}`)
+		assertJSON(t, "groups", entry["crew"], `{"captain":"picard"}`)
+	})
+
+	t.Run("does not map the attributes of other records", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := logEntry(t, logging.NewDatadogHandler(buf, logging.LevelTrace), buf,
+			logging.MethodKey, "warp",
+			logging.DurationKey, "soon",
+		)
+
+		if _, ok := entry["http"]; ok {
+			t.Errorf("http object was written: %v", entry["http"])
+		}
+
+		if entry["method"] != "warp" || entry["duration"] != "soon" {
+			t.Errorf("attributes are not equal: %v, %v != warp, soon", entry["method"], entry["duration"])
+		}
+	})
+}
@@ -0,0 +1,11 @@
+// Package logging provides request logging middleware and gRPC interceptors,
+// along with slog handlers formatting records for a number of log management
+// services.
+//
+// # Levels
+//
+// The constructor of each handler accepts a slog.Leveler, so that the level
+// may be changed at runtime by passing a *slog.LevelVar, such as one
+// registered with a LevelController. A fixed level is passed as a slog.Level,
+// for example LevelInfo.
+package logging
@@ -0,0 +1,266 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// ECSVersion is the version of the Elastic Common Schema written by an
+// ECSHandler.
+const ECSVersion = "8.11.0"
+
+// Elastic Common Schema specific attributes. The built-in and correlation
+// attributes use the dotted keys of the ECS logging specification, whereas
+// request attributes are written as nested objects.
+// https://www.elastic.co/guide/en/ecs-logging/overview/current/intro.html
+const (
+	ecsErrorKey     = "error"
+	ecsLevelKey     = "log.level"
+	ecsMessageKey   = "message"
+	ecsOriginKey    = "log.origin"
+	ecsSpanKey      = "span.id"
+	ecsTimestampKey = "@timestamp"
+	ecsTraceKey     = "trace.id"
+	ecsVersionKey   = "ecs.version"
+)
+
+// ECSHandler is a handler that formats log messages in a way that is
+// compatible with the Elastic Common Schema (ECS), for ingestion by
+// Elasticsearch.
+//
+// The SpanHandler and TraceHandler hooks correlate log entries with Elastic
+// APM. They default to SpanIDHandler and TraceIDHandler, which provide the
+// W3C trace and span IDs used by ECS.
+type ECSHandler struct {
+	handler      slog.Handler
+	SpanHandler  AttrHandler
+	TraceHandler AttrHandler
+
+	// The ECS fields are written outside of any open groups.
+	groups handlerGroups
+	fields []slog.Attr
+}
+
+// NewECSHandler returns a new ECSHandler.
+func NewECSHandler(w io.Writer, level slog.Leveler) *ECSHandler {
+	return &ECSHandler{
+		handler: slog.HandlerOptions{
+			Level: level,
+
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				// Only the built-in attributes are renamed, and these are never
+				// within a group.
+				if len(groups) > 0 {
+					return a
+				}
+
+				switch a.Key {
+				case slog.TimeKey:
+					a.Key = ecsTimestampKey
+				case slog.LevelKey:
+					a.Key = ecsLevelKey
+					a.Value = slog.StringValue(strings.ToLower(severityValue(a.Value).String()))
+				case slog.MessageKey:
+					a.Key = ecsMessageKey
+				case slog.SourceKey:
+					a.Key = ecsOriginKey
+				}
+
+				return a
+			},
+		}.NewJSONHandler(w),
+		SpanHandler:  SpanIDHandler(),
+		TraceHandler: TraceIDHandler(),
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level. The
+// handler ignores records whose level is lower.
+func (h *ECSHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle formats its argument Record as a JSON object on a single line.
+func (h *ECSHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	fields := append(make([]slog.Attr, 0, len(h.fields)), h.fields...)
+	request := isRequestRecord(r)
+
+	// Separate out the attributes with an equivalent ECS field.
+	r.Attrs(func(a slog.Attr) {
+		if isRequestMarker(a) {
+			return
+		}
+		if isECSAttr(a, request) {
+			fields = append(fields, a)
+			return
+		}
+		attrs = append(attrs, a)
+	})
+
+	// Nest the record attributes within any open groups, along with the
+	// attributes added to each group.
+	attrs = append(h.groups.nest(attrs), ecsFields(fields)...)
+	attrs = append(attrs, slog.String(ecsVersionKey, ECSVersion))
+
+	if h.SpanHandler != nil {
+		if span := h.SpanHandler(ctx); span != NilValue {
+			attrs = append(attrs, slog.Any(ecsSpanKey, span))
+		}
+	}
+	if h.TraceHandler != nil {
+		if trace := h.TraceHandler(ctx); trace != NilValue {
+			attrs = append(attrs, slog.Any(ecsTraceKey, trace))
+		}
+	}
+
+	// Create a new record with the attributes we want to keep.
+	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	record.AddAttrs(attrs...)
+
+	return h.handler.Handle(ctx, record)
+}
+
+// WithAttrs returns a new ECSHandler whose attributes consists of h's
+// attributes followed by attrs. Errors are always written to the error field,
+// even when added within a group.
+func (h *ECSHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := h.clone()
+
+	regular := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if isECSAttr(a, false) {
+			h2.fields = append(h2.fields, a)
+			continue
+		}
+		regular = append(regular, a)
+	}
+
+	h2.groups = h2.groups.withAttrs(regular)
+	return h2
+}
+
+// WithGroup returns a new ECSHandler whose attributes consists of h's
+// attributes followed by a group with the given name.
+func (h *ECSHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := h.clone()
+	h2.groups = h2.groups.withGroup(name)
+	return h2
+}
+
+// clone copies h such that appending fields to the copy leaves h unchanged.
+func (h *ECSHandler) clone() *ECSHandler {
+	h2 := *h
+	h2.fields = h.fields[:len(h.fields):len(h.fields)]
+	return &h2
+}
+
+// isECSAttr reports whether a has an equivalent ECS field. Only errors are
+// considered unless a is an attribute of a request record, since an
+// application may log attributes of the same name for other purposes.
+// Truncated payloads are logged as a group describing the truncation, which
+// has no equivalent.
+func isECSAttr(a slog.Attr, request bool) bool {
+	if a.Key == ecsErrorKey {
+		return true
+	}
+	if !request {
+		return false
+	}
+
+	switch a.Key {
+	case RequestKey, ResponseKey:
+		return a.Value.Kind() != slog.KindGroup
+	default:
+		return isHTTPRequestAttr(a)
+	}
+}
+
+// ecsFields converts attributes to their equivalent ECS fields.
+// https://www.elastic.co/guide/en/ecs/current/ecs-field-reference.html
+func ecsFields(attrs []slog.Attr) []slog.Attr {
+	var (
+		client, errs, event, server, url, userAgent        []slog.Attr
+		http, request, requestBody, response, responseBody []slog.Attr
+	)
+
+	for _, a := range attrs {
+		switch a.Key {
+		case MethodKey:
+			request = append(request, slog.String("method", a.Value.String()))
+		case RefererKey:
+			request = append(request, slog.String("referrer", a.Value.String()))
+		case RequestSizeKey:
+			requestBody = append(requestBody, slog.Any("bytes", a.Value))
+		case RequestKey:
+			requestBody = append(requestBody, slog.String("content", payloadContent(a.Value)))
+		case StatusKey:
+			response = append(response, slog.Any("status_code", a.Value))
+		case ResponseSizeKey:
+			responseBody = append(responseBody, slog.Any("bytes", a.Value))
+		case ResponseKey:
+			responseBody = append(responseBody, slog.String("content", payloadContent(a.Value)))
+		case ProtocolKey:
+			http = append(http, slog.String("version", strings.TrimPrefix(a.Value.String(), "HTTP/")))
+		case PathKey:
+			url = append(url, slog.String("path", a.Value.String()))
+		case URLKey:
+			url = append(url, slog.String("full", a.Value.String()))
+		case DurationKey:
+			if d, ok := durationValue(a.Value); ok {
+				event = append(event, slog.Int64("duration", d.Nanoseconds()))
+			}
+		case UserAgentKey:
+			userAgent = append(userAgent, slog.String("original", a.Value.String()))
+		case RemoteIPKey:
+			client = append(client, slog.String("address", a.Value.String()))
+		case ServerIPKey:
+			server = append(server, slog.String("address", a.Value.String()))
+		case ecsErrorKey:
+			errs = append(errs, slog.String("message", a.Value.String()))
+		}
+	}
+
+	request = appendGroup(request, "body", requestBody)
+	response = appendGroup(response, "body", responseBody)
+	http = appendGroup(http, "request", request)
+	http = appendGroup(http, "response", response)
+
+	fields := make([]slog.Attr, 0, 8)
+	fields = appendGroup(fields, "http", http)
+	fields = appendGroup(fields, "url", url)
+	fields = appendGroup(fields, "event", event)
+	fields = appendGroup(fields, "user_agent", userAgent)
+	fields = appendGroup(fields, "client", client)
+	fields = appendGroup(fields, "server", server)
+	fields = appendGroup(fields, "error", errs)
+
+	return fields
+}
+
+// payloadContent returns a payload as a string of JSON, which is how ECS
+// expects the content of a body.
+func payloadContent(v slog.Value) string {
+	if v.Kind() != slog.KindAny {
+		return v.String()
+	}
+
+	b, err := json.Marshal(v.Any())
+	if err != nil {
+		return v.String()
+	}
+
+	return string(b)
+}
@@ -0,0 +1,102 @@
+package logging_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+func TestECSHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("renames the built-in attributes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := logEntry(t, logging.NewECSHandler(buf, logging.LevelTrace), buf)
+
+		for key, expected := range map[string]string{
+			"log.level":   "info",
+			"message":     "beam me up",
+			"ecs.version": logging.ECSVersion,
+		} {
+			if entry[key] != expected {
+				t.Errorf("%s values are not equal: %v != %s", key, entry[key], expected)
+			}
+		}
+
+		if _, ok := entry["@timestamp"]; !ok {
+			t.Error("timestamp was not written")
+		}
+	})
+
+	t.Run("maps custom levels to lowercase level names", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		slog.New(logging.NewECSHandler(buf, logging.LevelTrace)).Log(traceCtx, logging.LevelNotice, "beam me up")
+
+		if !bytes.Contains(buf.Bytes(), []byte(`"log.level":"notice"`)) {
+			t.Errorf("level was not written: %s", buf.Bytes())
+		}
+	})
+
+	t.Run("adds the trace and span IDs", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := logEntry(t, logging.NewECSHandler(buf, logging.LevelTrace), buf)
+
+		if entry["trace.id"] != traceID {
+			t.Errorf("traces are not equal: %v != %s", entry["trace.id"], traceID)
+		}
+
+		if entry["span.id"] != spanID {
+			t.Errorf("spans are not equal: %v != %s", entry["span.id"], spanID)
+		}
+	})
+
+	t.Run("maps request attributes to ECS fields", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := logEntry(t, logging.NewECSHandler(buf, logging.LevelTrace), buf,
+			logging.RequestMarker,
+			logging.MethodKey, "GET",
+			logging.PathKey, "/bridge",
+			logging.StatusKey, 200,
+			logging.DurationKey, 1500*time.Millisecond,
+			logging.ResponseKey, map[string]string{"ship": "enterprise"},
+		)
+
+		assertJSON(t, "http objects", entry["http"], `{"request":{"method":"GET"},"response":{"body":{"content":"{\"ship\":\"enterprise\"}"},"status_code":200}}`)
+		assertJSON(t, "url objects", entry["url"], `{"path":"/bridge"}`)
+		assertJSON(t, "event objects", entry["event"], `{"duration":1500000000}`)
+
+		if _, ok := entry[logging.ResponseKey]; ok {
+			t.Error("response payload was written outside of the http object")
+		}
+	})
+
+	t.Run("keeps ECS fields at the top level within a group", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := logging.NewECSHandler(buf, logging.LevelTrace).WithGroup("crew")
+
+		entry := logEntry(t, h, buf, logging.RequestMarker, logging.MethodKey, "GET", logging.StatusKey, 200, "captain", "picard")
+
+		assertJSON(t, "http objects", entry["http"], `{"request":{"method":"GET"},"response":{"status_code":200}}`)
+		assertJSON(t, "groups", entry["crew"], `{"captain":"picard"}`)
+	})
+
+	t.Run("does not map the attributes of other records", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := logEntry(t, logging.NewECSHandler(buf, logging.LevelTrace), buf,
+			logging.StatusKey, "done",
+			logging.PathKey, "/tmp/x",
+		)
+
+		if _, ok := entry["http"]; ok {
+			t.Errorf("http object was written: %v", entry["http"])
+		}
+
+		if entry["status"] != "done" || entry["path"] != "/tmp/x" {
+			t.Errorf("attributes are not equal: %v, %v != done, /tmp/x", entry["status"], entry["path"])
+		}
+	})
+}
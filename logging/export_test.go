@@ -0,0 +1,15 @@
+package logging
+
+import "time"
+
+// RequestMarker is the attribute marking the records of requests, exported
+// so that handlers may be tested with the records of requests.
+var RequestMarker = requestMarkerAttr
+
+// SetSamplingClock replaces the clock measuring the intervals of h, so that
+// their ends may be tested without waiting for them.
+func SetSamplingClock(h *SamplingHandler, now func() time.Time) {
+	h.sampler.mu.Lock()
+	defer h.sampler.mu.Unlock()
+	h.sampler.now = now
+}
@@ -0,0 +1,169 @@
+package logging
+
+import (
+	"runtime"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// Google Cloud Error Reporting specific attributes.
+// https://cloud.google.com/error-reporting/docs/formatting-error-messages
+const (
+	googleCloudContextKey        = "context"
+	googleCloudErrorKey          = "error"
+	googleCloudReportLocationKey = "reportLocation"
+	googleCloudServiceContextKey = "serviceContext"
+	googleCloudTypeKey           = "@type"
+
+	googleCloudReportedErrorEventType = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+)
+
+// serviceContext identifies the service reporting errors to Error Reporting.
+type serviceContext struct {
+	service string
+	version string
+}
+
+// WithErrorReporting returns a new GoogleCloudHandler that formats records at
+// the error level and above as events for Google Cloud Error Reporting, such
+// that they are grouped with other occurrences of the same error. Each event
+// carries the service and version reporting it, the HTTP request being
+// handled, and the location the error was reported from.
+//
+// The message of a record logged by a LeveledLogger is followed by the stack
+// trace of the call to the logger, in the format of a Go panic, from which
+// Error Reporting determines where the error occurred. Records written by
+// RequestLogger and the gRPC interceptors have no such stack, so are reported
+// from the path of the request instead.
+func (h *GoogleCloudHandler) WithErrorReporting(service, version string) slog.Handler {
+	h2 := h.clone()
+	h2.serviceContext = &serviceContext{service: service, version: version}
+	return h2
+}
+
+// errorEvent returns the message and attributes of a ReportedErrorEvent for r.
+// The message is that of the record or, where it has none, the error logged
+// with it or a summary of the request, followed by the stack of the call to
+// the logger, if any. Without a stack the event is reported from the location
+// of the record, and no attributes are returned for records without a
+// location, which Error Reporting would reject.
+func (h *GoogleCloudHandler) errorEvent(r slog.Record, httpRequest []slog.Attr, errMsg, stack string) (string, []slog.Attr) {
+	msg := r.Message
+	if msg == "" {
+		msg = errMsg
+	}
+	if msg == "" {
+		msg = requestSummary(httpRequest)
+	}
+
+	var location []slog.Attr
+	if stack != "" {
+		msg += "\n\n" + stack
+	} else if location = reportLocation(r.PC, httpRequest); len(location) == 0 {
+		return r.Message, nil
+	}
+
+	service := []slog.Attr{slog.String("service", h.serviceContext.service)}
+	if h.serviceContext.version != "" {
+		service = append(service, slog.String("version", h.serviceContext.version))
+	}
+
+	var eventContext []slog.Attr
+	if req := errorReportingHTTPRequest(httpRequest); len(req) > 0 {
+		eventContext = append(eventContext, slog.Group(googleCloudHTTPRequestKey, req...))
+	}
+	if len(location) > 0 {
+		eventContext = append(eventContext, slog.Group(googleCloudReportLocationKey, location...))
+	}
+
+	return msg, []slog.Attr{
+		slog.String(googleCloudTypeKey, googleCloudReportedErrorEventType),
+		slog.Group(googleCloudServiceContextKey, service...),
+		slog.Group(googleCloudContextKey, eventContext...),
+	}
+}
+
+// reportLocation returns the attributes of the ReportLocation object for the
+// call to the logger at pc or, if there was none, the path of the request.
+func reportLocation(pc uintptr, httpRequest []slog.Attr) []slog.Attr {
+	if pc != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		return []slog.Attr{
+			slog.String("filePath", frame.File),
+			slog.Int("lineNumber", frame.Line),
+			slog.String("functionName", frame.Function),
+		}
+	}
+
+	for _, a := range httpRequest {
+		if a.Key == PathKey {
+			return []slog.Attr{slog.String("functionName", a.Value.String())}
+		}
+	}
+
+	return nil
+}
+
+// requestSummary describes the request, such as "GET /bridge: 500", for use
+// as the message of a request record which failed without an error.
+func requestSummary(httpRequest []slog.Attr) string {
+	var method, path, status string
+	for _, a := range httpRequest {
+		switch a.Key {
+		case MethodKey:
+			method = a.Value.String()
+		case PathKey:
+			path = a.Value.String()
+		case StatusKey:
+			status = a.Value.String()
+		}
+	}
+
+	if path == "" {
+		return ""
+	}
+
+	summary := strings.TrimSpace(method + " " + path)
+	if status != "" {
+		summary += ": " + status
+	}
+
+	return summary
+}
+
+// errorReportingHTTPRequest converts HTTP request attributes to the format of
+// the Error Reporting HttpRequestContext object, which differs from that of
+// the LogEntry.
+func errorReportingHTTPRequest(attrs []slog.Attr) []slog.Attr {
+	httpRequest := make([]slog.Attr, 0, len(attrs))
+
+	var path string
+	hasURL := false
+
+	for _, a := range attrs {
+		switch a.Key {
+		case MethodKey:
+			httpRequest = append(httpRequest, slog.String("method", a.Value.String()))
+		case StatusKey:
+			httpRequest = append(httpRequest, slog.Any("responseStatusCode", a.Value))
+		case PathKey:
+			path = a.Value.String()
+		case URLKey:
+			hasURL = true
+			httpRequest = append(httpRequest, slog.String("url", a.Value.String()))
+		case UserAgentKey:
+			httpRequest = append(httpRequest, slog.String("userAgent", a.Value.String()))
+		case RefererKey:
+			httpRequest = append(httpRequest, slog.String("referrer", a.Value.String()))
+		case RemoteIPKey:
+			httpRequest = append(httpRequest, slog.String("remoteIp", a.Value.String()))
+		}
+	}
+
+	if !hasURL && path != "" {
+		httpRequest = append(httpRequest, slog.String("url", path))
+	}
+
+	return httpRequest
+}
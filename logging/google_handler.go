@@ -10,6 +10,7 @@ import (
 // Google Cloud Logging specific attributes.
 // https://cloud.google.com/logging/docs/agent/logging/configuration#process-payload
 const (
+	googleCloudHTTPRequestKey    = "httpRequest"
 	googleCloudLabelsKey         = "logging.googleapis.com/labels"
 	googleCloudMessageKey        = "message"
 	googleCloudMethodKey         = "requestMethod"
@@ -17,29 +18,65 @@ const (
 	googleCloudSourceLocationKey = "logging.googleapis.com/sourceLocation"
 	googleCloudSpanKey           = "logging.googleapis.com/spanId"
 	googleCloudTraceKey          = "logging.googleapis.com/trace"
-	googleCloudResponseSizeKey   = "responseSize"
+	googleCloudTraceSampledKey   = "logging.googleapis.com/trace_sampled"
+)
+
+// Google Cloud Logging HttpRequest attributes.
+// https://cloud.google.com/logging/docs/reference/v2/rest/v2/LogEntry#HttpRequest
+const (
+	googleCloudLatencyKey      = "latency"
+	googleCloudProtocolKey     = "protocol"
+	googleCloudRefererKey      = "referer"
+	googleCloudRemoteIPKey     = "remoteIp"
+	googleCloudRequestSizeKey  = "requestSize"
+	googleCloudRequestURLKey   = "requestUrl"
+	googleCloudResponseSizeKey = "responseSize"
+	googleCloudServerIPKey     = "serverIp"
+	googleCloudStatusKey       = "status"
+	googleCloudUserAgentKey    = "userAgent"
 )
 
 // GoogleCloudHandler is a handler that formats log messages in a way that is
 // compatible with Google Cloud Logging.
+//
+// The SpanHandler, TraceHandler and TraceSampledHandler hooks may be used to
+// correlate log entries with Cloud Trace. For requests carrying trace headers
+// GoogleCloudTraceHandler, SpanIDHandler and GoogleCloudTraceSampledHandler
+// provide these values.
 type GoogleCloudHandler struct {
-	handler      slog.Handler
-	SpanHandler  AttrHandler
-	TraceHandler AttrHandler
+	handler             slog.Handler
+	SpanHandler         AttrHandler
+	TraceHandler        AttrHandler
+	TraceSampledHandler AttrHandler
+
+	// State accumulated by WithAttrs, WithGroup and WithLabels, held here so
+	// that the LogEntry fields stay at the top level of the entry.
+	groups handlerGroups
+	labels map[string]string
+
+	// serviceContext is set by WithErrorReporting, enabling the formatting of
+	// records as Error Reporting events.
+	serviceContext *serviceContext
 }
 
-// NewGoogleCloudHandler returns a new
-func NewGoogleCloudHandler(w io.Writer, level slog.Level) *GoogleCloudHandler {
+// NewGoogleCloudHandler returns a new GoogleCloudHandler.
+func NewGoogleCloudHandler(w io.Writer, level slog.Leveler) *GoogleCloudHandler {
 	return &GoogleCloudHandler{
 		handler: slog.HandlerOptions{
 			Level: level,
 
-			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 				if len(a.Value.String()) == 0 {
 					a.Key = "" // Drop empty attributes.
 					return a
 				}
 
+				// Only the built-in attributes are renamed, and these are never
+				// within a group.
+				if len(groups) > 0 {
+					return a
+				}
+
 				switch a.Key {
 				case slog.LevelKey:
 					a.Key = googleCloudSeverityKey
@@ -48,8 +85,6 @@ func NewGoogleCloudHandler(w io.Writer, level slog.Level) *GoogleCloudHandler {
 					a.Key = googleCloudMessageKey
 				case slog.SourceKey:
 					a.Key = googleCloudSourceLocationKey
-				case MethodKey:
-					a.Key = googleCloudMethodKey
 				}
 
 				return a
@@ -67,19 +102,45 @@ func (h *GoogleCloudHandler) Enabled(ctx context.Context, level slog.Level) bool
 // Handle formats its argument Record as a JSON object on a single line.
 func (h *GoogleCloudHandler) Handle(ctx context.Context, r slog.Record) error {
 	attrs := make([]slog.Attr, 0, r.NumAttrs())
-	httpRequest := make([]slog.Attr, 0)
+	request := isRequestRecord(r)
 
-	// Separate out the HTTP request attributes.
+	// Separate out the HTTP request attributes of a request record.
+	var (
+		httpRequest []slog.Attr
+		errMsg      string
+	)
 	r.Attrs(func(a slog.Attr) {
-		switch a.Key {
-		case MethodKey, StatusKey:
+		if isRequestMarker(a) {
+			return
+		}
+		if request && isHTTPRequestAttr(a) {
 			httpRequest = append(httpRequest, a)
-		default:
-			attrs = append(attrs, a)
+			return
+		}
+		if a.Key == googleCloudErrorKey {
+			errMsg = a.Value.String()
 		}
+		attrs = append(attrs, a)
 	})
 
-	attrs = append(attrs, slog.Group("httpRequest", httpRequest...))
+	// Nest the record attributes within any open groups, along with the
+	// attributes added to each group.
+	attrs = h.groups.nest(attrs)
+
+	msg := r.Message
+	if h.serviceContext != nil && r.Level >= LevelError {
+		var event []slog.Attr
+		msg, event = h.errorEvent(r, httpRequest, errMsg, callStackFromContext(ctx))
+		attrs = append(attrs, event...)
+	}
+
+	if len(httpRequest) > 0 {
+		attrs = append(attrs, slog.Group(googleCloudHTTPRequestKey, googleCloudHTTPRequest(httpRequest)...))
+	}
+
+	if len(h.labels) > 0 {
+		attrs = append(attrs, slog.Any(googleCloudLabelsKey, h.labels))
+	}
 
 	if h.SpanHandler != nil {
 		if span := h.SpanHandler(ctx); span != NilValue {
@@ -91,37 +152,123 @@ func (h *GoogleCloudHandler) Handle(ctx context.Context, r slog.Record) error {
 			attrs = append(attrs, slog.Any(googleCloudTraceKey, trace))
 		}
 	}
+	if h.TraceSampledHandler != nil {
+		if sampled := h.TraceSampledHandler(ctx); sampled != NilValue {
+			attrs = append(attrs, slog.Any(googleCloudTraceSampledKey, sampled))
+		}
+	}
 
 	// Create a new record with the attributes we want to keep.
-	record := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	record := slog.NewRecord(r.Time, r.Level, msg, r.PC)
 	record.AddAttrs(attrs...)
 
-	h.handler.Handle(ctx, record)
-	return nil
+	return h.handler.Handle(ctx, record)
 }
 
 // WithAttrs returns a new GoogleCloudHandler whose attributes consists of h's
 // attributes followed by attrs.
 func (h *GoogleCloudHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &GoogleCloudHandler{
-		handler: h.handler.WithAttrs(attrs),
+	if len(attrs) == 0 {
+		return h
 	}
+
+	h2 := h.clone()
+	h2.groups = h2.groups.withAttrs(attrs)
+	return h2
 }
 
 // WithGroup returns a new GoogleCloudHandler whose attributes consists of h's
 // attributes followed by a group with the given name.
 func (h *GoogleCloudHandler) WithGroup(name string) slog.Handler {
-	return &GoogleCloudHandler{
-		handler: h.handler.WithGroup(name),
+	if name == "" {
+		return h
 	}
+
+	h2 := h.clone()
+	h2.groups = h2.groups.withGroup(name)
+	return h2
 }
 
-// WithLabels returns a new GoogleCloudHandler whose attributes consists of h's
-// attributes followed by the given labels.
+// WithLabels returns a new GoogleCloudHandler whose labels consists of h's
+// labels merged with the given labels. Where a label is already present its
+// value is replaced.
 func (h *GoogleCloudHandler) WithLabels(labels map[string]string) slog.Handler {
-	return &GoogleCloudHandler{
-		handler: h.handler.WithAttrs([]slog.Attr{
-			slog.Any(googleCloudLabelsKey, labels),
-		}),
+	h2 := h.clone()
+
+	h2.labels = make(map[string]string, len(h.labels)+len(labels))
+	for k, v := range h.labels {
+		h2.labels[k] = v
+	}
+	for k, v := range labels {
+		h2.labels[k] = v
+	}
+
+	return h2
+}
+
+// clone returns a copy of h, including its hooks, whose accumulated state may
+// be extended without affecting h.
+func (h *GoogleCloudHandler) clone() *GoogleCloudHandler {
+	h2 := *h
+	return &h2
+}
+
+// isHTTPRequestAttr reports whether a is an attribute of the httpRequest
+// object. Only the attributes of request records are considered, since an
+// application may log attributes of the same name for other purposes.
+func isHTTPRequestAttr(a slog.Attr) bool {
+	switch a.Key {
+	case MethodKey, StatusKey, DurationKey, PathKey, URLKey, RequestSizeKey,
+		ResponseSizeKey, UserAgentKey, RemoteIPKey, ServerIPKey, RefererKey,
+		ProtocolKey:
+		return true
+	default:
+		return false
 	}
 }
+
+// googleCloudHTTPRequest converts HTTP request attributes to the format of
+// the LogEntry HttpRequest object.
+func googleCloudHTTPRequest(attrs []slog.Attr) []slog.Attr {
+	httpRequest := make([]slog.Attr, 0, len(attrs))
+
+	var path string
+	hasURL := false
+
+	for _, a := range attrs {
+		switch a.Key {
+		case MethodKey:
+			httpRequest = append(httpRequest, slog.String(googleCloudMethodKey, a.Value.String()))
+		case StatusKey:
+			httpRequest = append(httpRequest, slog.Any(googleCloudStatusKey, a.Value))
+		case DurationKey:
+			httpRequest = append(httpRequest, slog.String(googleCloudLatencyKey, latencyValue(a.Value)))
+		case PathKey:
+			path = a.Value.String()
+		case URLKey:
+			hasURL = true
+			httpRequest = append(httpRequest, slog.String(googleCloudRequestURLKey, a.Value.String()))
+		case RequestSizeKey:
+			httpRequest = append(httpRequest, slog.String(googleCloudRequestSizeKey, a.Value.String()))
+		case ResponseSizeKey:
+			httpRequest = append(httpRequest, slog.String(googleCloudResponseSizeKey, a.Value.String()))
+		case UserAgentKey:
+			httpRequest = append(httpRequest, slog.String(googleCloudUserAgentKey, a.Value.String()))
+		case RemoteIPKey:
+			httpRequest = append(httpRequest, slog.String(googleCloudRemoteIPKey, a.Value.String()))
+		case ServerIPKey:
+			httpRequest = append(httpRequest, slog.String(googleCloudServerIPKey, a.Value.String()))
+		case RefererKey:
+			httpRequest = append(httpRequest, slog.String(googleCloudRefererKey, a.Value.String()))
+		case ProtocolKey:
+			httpRequest = append(httpRequest, slog.String(googleCloudProtocolKey, a.Value.String()))
+		}
+	}
+
+	// Without an absolute URL the path is the best available substitute.
+	if !hasURL && path != "" {
+		httpRequest = append(httpRequest, slog.String(googleCloudRequestURLKey, path))
+	}
+
+	return httpRequest
+}
@@ -0,0 +1,447 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/kapetndev/connect/logging"
+	"github.com/kapetndev/connect/logging/logtest"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+)
+
+var traceCtx = logging.NewTraceContext(context.Background(), logging.TraceContext{
+	TraceID: traceID,
+	SpanID:  spanID,
+	Sampled: true,
+})
+
+func newGoogleCloudHandler(buf *bytes.Buffer) *logging.GoogleCloudHandler {
+	h := logging.NewGoogleCloudHandler(buf, logging.LevelTrace)
+	h.SpanHandler = logging.SpanIDHandler()
+	h.TraceHandler = logging.GoogleCloudTraceHandler("enterprise")
+	h.TraceSampledHandler = logging.GoogleCloudTraceSampledHandler()
+	return h
+}
+
+func logEntry(t *testing.T, h slog.Handler, buf *bytes.Buffer, attrs ...any) map[string]interface{} {
+	buf.Reset()
+	slog.New(h).InfoCtx(traceCtx, "beam me up", attrs...)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to decode log entry: %s", err)
+	}
+
+	return entry
+}
+
+func assertTrace(t *testing.T, entry map[string]interface{}) {
+	t.Helper()
+
+	expectedTrace := "projects/enterprise/traces/" + traceID
+	if entry["logging.googleapis.com/trace"] != expectedTrace {
+		t.Errorf("traces are not equal: %v != %s", entry["logging.googleapis.com/trace"], expectedTrace)
+	}
+
+	if entry["logging.googleapis.com/spanId"] != spanID {
+		t.Errorf("spans are not equal: %v != %s", entry["logging.googleapis.com/spanId"], spanID)
+	}
+
+	if entry["logging.googleapis.com/trace_sampled"] != true {
+		t.Errorf("trace sampled flags are not equal: %v != %t", entry["logging.googleapis.com/trace_sampled"], true)
+	}
+}
+
+func assertJSON(t *testing.T, name string, v interface{}, expected string) {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != expected {
+		t.Errorf("%s are not equal: %s != %s", name, b, expected)
+	}
+}
+
+func TestGoogleCloudHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("renames the built-in attributes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := logEntry(t, newGoogleCloudHandler(buf), buf)
+
+		if entry["severity"] != "INFO" {
+			t.Errorf("severities are not equal: %v != %s", entry["severity"], "INFO")
+		}
+
+		if entry["message"] != "beam me up" {
+			t.Errorf("messages are not equal: %v != %s", entry["message"], "beam me up")
+		}
+
+		assertTrace(t, entry)
+	})
+
+	t.Run("moves request attributes into the httpRequest object", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := logEntry(t, newGoogleCloudHandler(buf), buf,
+			logging.RequestMarker,
+			logging.MethodKey, "GET",
+			logging.PathKey, "/bridge",
+			logging.StatusKey, 200,
+			logging.DurationKey, 1500000000,
+		)
+
+		assertJSON(t, "httpRequest objects", entry["httpRequest"], `{"latency":"1.5s","requestMethod":"GET","requestUrl":"/bridge","status":200}`)
+	})
+
+	t.Run("moves the attributes of requests logged by RequestLogger", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		handler := logging.RequestLogger(logging.WithHandler(newGoogleCloudHandler(buf)))(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bridge", nil))
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		httpRequest, _ := entry["httpRequest"].(map[string]interface{})
+
+		if httpRequest["requestMethod"] != "GET" || httpRequest["status"] != float64(http.StatusTeapot) {
+			t.Errorf("httpRequest objects are not equal: %v", httpRequest)
+		}
+
+		if _, ok := entry[logging.StatusKey]; ok {
+			t.Error("status was written outside of the httpRequest object")
+		}
+	})
+
+	t.Run("does not move the attributes of other records", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		entry := logEntry(t, newGoogleCloudHandler(buf), buf,
+			"status", "done",
+			"path", "/tmp/x",
+			"duration", "soon",
+		)
+
+		if _, ok := entry["httpRequest"]; ok {
+			t.Errorf("httpRequest object was written: %v", entry["httpRequest"])
+		}
+
+		for key, expected := range map[string]string{"status": "done", "path": "/tmp/x", "duration": "soon"} {
+			if entry[key] != expected {
+				t.Errorf("%s values are not equal: %v != %s", key, entry[key], expected)
+			}
+		}
+	})
+}
+
+func TestGoogleCloudHandler_WithAttrs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps the trace hooks", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf).WithAttrs([]slog.Attr{slog.String("ship", "enterprise")})
+
+		entry := logEntry(t, h, buf)
+		assertTrace(t, entry)
+
+		if entry["ship"] != "enterprise" {
+			t.Errorf("attributes are not equal: %v != %s", entry["ship"], "enterprise")
+		}
+	})
+
+	t.Run("does not move attributes added to the handler", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf).WithAttrs([]slog.Attr{slog.String(logging.MethodKey, "warp")})
+
+		entry := logEntry(t, h, buf, logging.RequestMarker, logging.StatusKey, 200)
+
+		assertJSON(t, "httpRequest objects", entry["httpRequest"], `{"status":200}`)
+
+		if entry["method"] != "warp" {
+			t.Errorf("attributes are not equal: %v != %s", entry["method"], "warp")
+		}
+	})
+
+	t.Run("does not modify the parent handler", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf)
+		h.WithAttrs([]slog.Attr{slog.String("ship", "enterprise")})
+
+		entry := logEntry(t, h, buf)
+		if _, ok := entry["ship"]; ok {
+			t.Error("parent handler has the attributes of the derived handler")
+		}
+	})
+}
+
+func TestGoogleCloudHandler_WithGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps the trace hooks at the top level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf).WithGroup("crew")
+
+		entry := logEntry(t, h, buf, "captain", "picard")
+		assertTrace(t, entry)
+		assertJSON(t, "groups", entry["crew"], `{"captain":"picard"}`)
+	})
+
+	t.Run("keeps the built-in and request attributes at the top level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf).WithGroup("crew")
+
+		entry := logEntry(t, h, buf, logging.RequestMarker, logging.MethodKey, "GET", "captain", "picard")
+
+		if entry["message"] != "beam me up" {
+			t.Errorf("messages are not equal: %v != %s", entry["message"], "beam me up")
+		}
+
+		assertJSON(t, "httpRequest objects", entry["httpRequest"], `{"requestMethod":"GET"}`)
+		assertJSON(t, "groups", entry["crew"], `{"captain":"picard"}`)
+	})
+
+	t.Run("nests attributes added before and after each group", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf).
+			WithAttrs([]slog.Attr{slog.String("ship", "enterprise")}).
+			WithGroup("crew").
+			WithAttrs([]slog.Attr{slog.String("captain", "picard")}).
+			WithGroup("bridge")
+
+		entry := logEntry(t, h, buf, "helm", "riker")
+		assertTrace(t, entry)
+
+		if entry["ship"] != "enterprise" {
+			t.Errorf("attributes are not equal: %v != %s", entry["ship"], "enterprise")
+		}
+
+		assertJSON(t, "groups", entry["crew"], `{"bridge":{"helm":"riker"},"captain":"picard"}`)
+	})
+
+	t.Run("omits groups without attributes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf).WithGroup("crew")
+
+		entry := logEntry(t, h, buf)
+		if _, ok := entry["crew"]; ok {
+			t.Error("empty group was written")
+		}
+	})
+}
+
+func TestGoogleCloudHandler_WithLabels(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps the trace hooks", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf).WithLabels(map[string]string{"ship": "enterprise"})
+
+		entry := logEntry(t, h, buf)
+		assertTrace(t, entry)
+		assertJSON(t, "labels", entry["logging.googleapis.com/labels"], `{"ship":"enterprise"}`)
+	})
+
+	t.Run("merges labels across calls", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf).
+			WithLabels(map[string]string{"ship": "enterprise", "registry": "NCC-1701"}).(*logging.GoogleCloudHandler).
+			WithLabels(map[string]string{"registry": "NCC-1701-D", "captain": "picard"})
+
+		entry := logEntry(t, h, buf)
+		assertJSON(t, "labels", entry["logging.googleapis.com/labels"], `{"captain":"picard","registry":"NCC-1701-D","ship":"enterprise"}`)
+	})
+
+	t.Run("keeps labels at the top level within a group", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf).
+			WithLabels(map[string]string{"ship": "enterprise"}).
+			WithGroup("crew")
+
+		entry := logEntry(t, h, buf, "captain", "picard")
+		assertTrace(t, entry)
+		assertJSON(t, "labels", entry["logging.googleapis.com/labels"], `{"ship":"enterprise"}`)
+		assertJSON(t, "groups", entry["crew"], `{"captain":"picard"}`)
+	})
+
+	t.Run("does not modify the labels of the parent handler", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		parent := newGoogleCloudHandler(buf).WithLabels(map[string]string{"ship": "enterprise"}).(*logging.GoogleCloudHandler)
+		parent.WithLabels(map[string]string{"captain": "picard"})
+
+		entry := logEntry(t, parent, buf)
+		assertJSON(t, "labels", entry["logging.googleapis.com/labels"], `{"ship":"enterprise"}`)
+	})
+}
+
+func TestGoogleCloudHandler_WithErrorReporting(t *testing.T) {
+	t.Parallel()
+
+	newHandler := func(buf *bytes.Buffer) slog.Handler {
+		return newGoogleCloudHandler(buf).WithErrorReporting("bridge", "1.0.0")
+	}
+
+	t.Run("formats error records as reported error events", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logging.New(newHandler(buf)).Error(traceCtx, "warp core breach",
+			logging.RequestMarker,
+			logging.MethodKey, "GET",
+			logging.PathKey, "/engineering",
+			logging.StatusKey, 500,
+			logging.UserAgentKey, "tricorder",
+		)
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		assertTrace(t, entry)
+
+		if typ := entry["@type"]; typ != "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent" {
+			t.Errorf("types are not equal: %v", typ)
+		}
+		assertJSON(t, "service context", entry["serviceContext"], `{"service":"bridge","version":"1.0.0"}`)
+		assertJSON(t, "HTTP request", entry["httpRequest"], `{"requestMethod":"GET","requestUrl":"/engineering","status":500,"userAgent":"tricorder"}`)
+
+		eventContext, _ := entry["context"].(map[string]interface{})
+		assertJSON(t, "context HTTP request", eventContext["httpRequest"], `{"method":"GET","responseStatusCode":500,"url":"/engineering","userAgent":"tricorder"}`)
+
+		// The message is followed by the stack of the call to the logger
+		// within this test, without the frames of the logger.
+		msg, _ := entry["message"].(string)
+		if !strings.HasPrefix(msg, "warp core breach\n\ngoroutine ") || !strings.Contains(msg, " [running]:\n") {
+			t.Errorf("message does not hold a stack trace: %s", msg)
+		}
+		if !strings.Contains(msg, "\ngithub.com/kapetndev/connect/logging_test.TestGoogleCloudHandler_WithErrorReporting.") {
+			t.Errorf("stack trace does not hold the caller: %s", msg)
+		}
+		if !strings.Contains(msg, "/google_handler_test.go:") {
+			t.Errorf("stack trace does not hold the location of the caller: %s", msg)
+		}
+		if strings.Contains(msg, "LeveledLogger") {
+			t.Errorf("stack trace holds the frames of the logger: %s", msg)
+		}
+
+		if _, ok := eventContext["reportLocation"]; ok {
+			t.Errorf("context contains reportLocation: %v", eventContext["reportLocation"])
+		}
+		if _, ok := entry["stack_trace"]; ok {
+			t.Errorf("entry contains stack_trace: %v", entry["stack_trace"])
+		}
+	})
+
+	t.Run("uses the error of records without a message", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logging.New(newHandler(buf)).Critical(context.Background(), "", "error", "shields failing")
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		if msg, _ := entry["message"].(string); !strings.HasPrefix(msg, "shields failing\n\ngoroutine ") {
+			t.Errorf("messages are not equal: %v != %s", msg, "shields failing")
+		}
+	})
+
+	t.Run("reports records without a stack from their location", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		slog.New(newHandler(buf)).Error("warp core breach", nil)
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		if msg := entry["message"]; msg != "warp core breach" {
+			t.Errorf("messages are not equal: %v != %s", msg, "warp core breach")
+		}
+
+		// The location is the call to the logger within this test.
+		eventContext, _ := entry["context"].(map[string]interface{})
+		location, _ := eventContext["reportLocation"].(map[string]interface{})
+		if fn, _ := location["functionName"].(string); !strings.HasPrefix(fn, "github.com/kapetndev/connect/logging_test.TestGoogleCloudHandler_WithErrorReporting.") {
+			t.Errorf("function names are not equal: %v", fn)
+		}
+		if file, _ := location["filePath"].(string); !strings.HasSuffix(file, "google_handler_test.go") {
+			t.Errorf("file paths are not equal: %v", file)
+		}
+		if line, _ := location["lineNumber"].(float64); line == 0 {
+			t.Errorf("line number was not set: %v", location["lineNumber"])
+		}
+	})
+
+	t.Run("reports failed calls from the method logged by the interceptor", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		closer, client, _ := logtest.NewServer(t, nil,
+			logging.WithHandler(newHandler(buf)),
+			logging.WithCodeLevels(func(codes.Code) slog.Level { return logging.LevelError }),
+		)
+		defer closer()
+
+		if _, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: "InvalidArgument"}); err == nil {
+			t.Fatal("error was <nil>")
+		}
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		if typ := entry["@type"]; typ != "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent" {
+			t.Errorf("types are not equal: %v", typ)
+		}
+		if msg := entry["message"]; msg != "rpc error: code = InvalidArgument desc = InvalidArgument" {
+			t.Errorf("messages are not equal: %v", msg)
+		}
+
+		eventContext, _ := entry["context"].(map[string]interface{})
+		assertJSON(t, "report location", eventContext["reportLocation"], `{"functionName":"/echo.v1.EchoService/Echo"}`)
+	})
+
+	t.Run("reports failed requests from the path logged by the RequestLogger", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logging.RequestLogger(logging.WithHandler(newHandler(buf)))(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/engineering", nil))
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		if typ := entry["@type"]; typ != "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent" {
+			t.Errorf("types are not equal: %v", typ)
+		}
+		if msg := entry["message"]; msg != "GET /engineering: 500" {
+			t.Errorf("messages are not equal: %v != %s", msg, "GET /engineering: 500")
+		}
+
+		eventContext, _ := entry["context"].(map[string]interface{})
+		assertJSON(t, "report location", eventContext["reportLocation"], `{"functionName":"/engineering"}`)
+	})
+
+	t.Run("omits the version when empty", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := newGoogleCloudHandler(buf).WithErrorReporting("bridge", "")
+		logging.New(h).Error(context.Background(), "warp core breach")
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		assertJSON(t, "service context", entry["serviceContext"], `{"service":"bridge"}`)
+	})
+
+	t.Run("does not report records below the error level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logging.New(newHandler(buf)).Warning(context.Background(), "shields at 50%")
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		for _, key := range []string{"@type", "serviceContext", "context"} {
+			if _, ok := entry[key]; ok {
+				t.Errorf("entry contains %s: %v", key, entry[key])
+			}
+		}
+	})
+
+	t.Run("does not modify the parent handler", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		parent := newGoogleCloudHandler(buf)
+		parent.WithErrorReporting("bridge", "1.0.0")
+		logging.New(parent).Error(context.Background(), "warp core breach")
+
+		entry := decodeLogEntry(t, buf.Bytes())
+		if _, ok := entry["@type"]; ok {
+			t.Errorf("entry contains @type: %v", entry["@type"])
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package logging
+
+import "golang.org/x/exp/slog"
+
+// groupOrAttrs holds either a group name or a list of attributes added to a
+// handler.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+// handlerGroups records the attributes and groups added to a handler by
+// WithAttrs and WithGroup so that they may be applied when a record is
+// handled. Handlers use this in place of the WithAttrs and WithGroup methods
+// of an underlying handler when some attributes must always be written at the
+// top level of an entry, regardless of any open groups.
+type handlerGroups []groupOrAttrs
+
+// withAttrs returns a copy of g followed by attrs.
+func (g handlerGroups) withAttrs(attrs []slog.Attr) handlerGroups {
+	if len(attrs) == 0 {
+		return g
+	}
+	return append(g[:len(g):len(g)], groupOrAttrs{attrs: attrs})
+}
+
+// withGroup returns a copy of g followed by a group with the given name.
+func (g handlerGroups) withGroup(name string) handlerGroups {
+	if name == "" {
+		return g
+	}
+	return append(g[:len(g):len(g)], groupOrAttrs{group: name})
+}
+
+// nest returns attrs nested within the groups of g, interleaved with the
+// attributes added before and after each group was opened.
+func (g handlerGroups) nest(attrs []slog.Attr) []slog.Attr {
+	for i := len(g) - 1; i >= 0; i-- {
+		goa := g[i]
+
+		if goa.group == "" {
+			attrs = append(goa.attrs[:len(goa.attrs):len(goa.attrs)], attrs...)
+			continue
+		}
+
+		// Empty groups are omitted entirely.
+		if len(attrs) == 0 {
+			continue
+		}
+
+		attrs = []slog.Attr{slog.Group(goa.group, attrs...)}
+	}
+
+	return attrs
+}
+
+// appendGroup appends a group with the given name and attributes to attrs,
+// unless the group would be empty.
+func appendGroup(attrs []slog.Attr, name string, group []slog.Attr) []slog.Attr {
+	if len(group) == 0 {
+		return attrs
+	}
+	return append(attrs, slog.Group(name, group...))
+}
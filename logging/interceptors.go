@@ -10,6 +10,8 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
 	"github.com/golang/protobuf/jsonpb"
@@ -21,84 +23,191 @@ import (
 // jsonpbMarshaller is the marshaller used for serializing protobuf messages.
 var jsonpbMarshaller = &jsonpb.Marshaler{}
 
+// Values of the KindKey attribute identifying which side of an RPC produced
+// the log entry.
+const (
+	kindClient = "client"
+	kindServer = "server"
+)
+
 // UnaryServerInterceptor is a server side unary interceptor logging the
 // payloads for a single request/response.
 func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
 	o := applyOptions(opts)
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		startTime := time.Now()
+		ctx = traceContextFromIncoming(ctx)
+
+		// Apply any rules matching the method.
+		o := o.forRoute(info.FullMethod)
+		sampled := o.sampled()
 
 		// Configure the logger passed into the middleware.
-		logger := New(o.handler)
+		h, buffer := o.requestHandler()
+		scope := newRequestScope(New(h))
+
+		// Write any buffered records should the handler panic.
+		if buffer != nil {
+			defer flushOnPanic(ctx, buffer)
+		}
 
 		// Invoke the handler and log the response.
-		resp, err := handler(NewContext(ctx, logger), req)
+		resp, err := handler(newRequestContext(ctx, scope), req)
+
+		// Buffered records are only written if the call failed.
+		if err != nil {
+			buffer.flush(ctx)
+		} else {
+			buffer.discard()
+		}
 
 		// Suppress request logs matching some pattern.
-		if o.shouldDiscard(ctx, info.FullMethod, err) {
+		if !sampled || o.shouldDiscard(ctx, info.FullMethod, err) {
 			return resp, err
 		}
 
 		if err != nil {
-			o.handler.Handle(ctx, newRPCErrorRecord(ctx, startTime, info.FullMethod, err))
+			record := newRPCErrorRecord(ctx, o.codeLevel(status.Code(err)), startTime, kindServer, info.FullMethod, err)
+			record.AddAttrs(scope.attrs()...)
+			record.AddAttrs(o.requestAttrs(req)...)
+			o.handle(ctx, record)
 			return resp, err
 		}
 
 		// Log the request/response.
-		o.handler.Handle(ctx, newRPCRecord(ctx, startTime, info.FullMethod, resp))
+		record := newRPCRecord(ctx, o.codeLevel(codes.OK), startTime, kindServer, info.FullMethod)
+		record.AddAttrs(scope.attrs()...)
+		record.AddAttrs(o.responseAttrs(resp)...)
+		record.AddAttrs(o.requestAttrs(req)...)
+		o.handle(ctx, record)
 		return resp, err
 	}
 }
 
 // StreamServerInterceptor is a server side stream interceptor logging the
 // payloads for a single stream. Unlike the unary interceptor the payload of
-// each message in the stream will be collected and logged together.
+// each message in the stream will be collected and logged together, along
+// with its direction and sequence number. The amount of payload captured is
+// limited by WithStreamPayloadLimit.
 func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 	o := applyOptions(opts)
 	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		startTime := time.Now()
-		ctx := ss.Context()
+		ctx := traceContextFromIncoming(ss.Context())
+
+		// Apply any rules matching the method.
+		o := o.forRoute(info.FullMethod)
+		sampled := o.sampled()
 
 		// Configure the logger passed into the middleware.
-		logger := New(o.handler)
+		h, buffer := o.requestHandler()
+		scope := newRequestScope(New(h))
 
-		ss, err := transport.NewServerStreamWithContext(NewContext(ctx, logger), ss)
+		// Write any buffered records should the handler panic.
+		if buffer != nil {
+			defer flushOnPanic(ctx, buffer)
+		}
+
+		ss, err := transport.NewServerStreamWithContext(newRequestContext(ctx, scope), ss)
 		if err != nil {
 			return status.Error(codes.Internal, err.Error())
 		}
 
+		// Wrap the stream so we may capture the payload of each message.
+		ps := &payloadServerStream{
+			streamCapture: newStreamCapture(o, sampled),
+			ServerStream:  ss,
+		}
+
 		// Invoke the handler and log the response.
-		err = handler(srv, ss)
+		err = handler(srv, ps)
+
+		// Buffered records are only written if the call failed.
+		if err != nil {
+			buffer.flush(ctx)
+		} else {
+			buffer.discard()
+		}
 
 		// Suppress request logs matching some pattern.
-		if o.shouldDiscard(ctx, info.FullMethod, err) {
+		if !sampled || o.shouldDiscard(ctx, info.FullMethod, err) {
 			return err
 		}
 
 		if err != nil {
-			o.handler.Handle(ctx, newRPCErrorRecord(ctx, startTime, info.FullMethod, err))
+			record := newRPCErrorRecord(ctx, o.codeLevel(status.Code(err)), startTime, kindServer, info.FullMethod, err)
+			record.AddAttrs(scope.attrs()...)
+			record.AddAttrs(ps.sizeAttrs()...)
+			record.AddAttrs(o.requestAttrs(ps.requests)...)
+			o.handle(ctx, record)
 			return err
 		}
 
 		// Log the request/response.
-		o.handler.Handle(ctx, newRPCRecord(ctx, startTime, info.FullMethod, nil))
+		record := newRPCRecord(ctx, o.codeLevel(codes.OK), startTime, kindServer, info.FullMethod)
+		record.AddAttrs(scope.attrs()...)
+		record.AddAttrs(ps.sizeAttrs()...)
+		record.AddAttrs(o.responseAttrs(ps.responses)...)
+		record.AddAttrs(o.requestAttrs(ps.requests)...)
+		o.handle(ctx, record)
 		return err
 	}
 }
 
-func newRPCErrorRecord(ctx context.Context, t time.Time, path string, err error) slog.Record {
-	record := newCommonRecord(ctx, slog.LevelError, t, "POST", path)
-	record.AddAttrs(slog.String("error", err.Error()))
+// traceContextFromIncoming propagates the trace context of an incoming call,
+// if any, so that log entries may be correlated with the trace.
+func traceContextFromIncoming(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	if tc, ok := TraceFromMetadata(md); ok {
+		return NewTraceContext(ctx, tc)
+	}
+
+	return ctx
+}
+
+func newRPCErrorRecord(ctx context.Context, level slog.Level, t time.Time, kind, path string, err error) slog.Record {
+	record := newCommonRPCRecord(ctx, level, t, kind, path)
+	record.AddAttrs(
+		slog.String(CodeKey, status.Code(err).String()),
+		slog.String("error", err.Error()),
+	)
+	return record
+}
+
+func newRPCRecord(ctx context.Context, level slog.Level, t time.Time, kind, path string) slog.Record {
+	record := newCommonRPCRecord(ctx, level, t, kind, path)
+	record.AddAttrs(slog.String(CodeKey, codes.OK.String()))
 	return record
 }
 
-func newRPCRecord(ctx context.Context, t time.Time, path string, pbMsg interface{}) slog.Record {
-	record := newCommonRecord(ctx, slog.LevelInfo, t, "POST", path)
+func newCommonRPCRecord(ctx context.Context, level slog.Level, t time.Time, kind, path string) slog.Record {
+	record := newCommonRecord(ctx, level, t, "POST", path)
+	record.AddAttrs(
+		slog.String(KindKey, kind),
+		slog.String(ProtocolKey, "HTTP/2"),
+	)
+
+	// The peer and incoming metadata are only present in the context of the
+	// server side of the call.
+	if kind != kindServer {
+		return record
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		record.AddAttrs(slog.String(RemoteIPKey, p.Addr.String()))
+	}
 
-	// If the response includes a payload then add it to the log entry. This
-	// assumes that the payload is a JSON object.
-	if p, ok := pbMsg.(proto.Message); ok {
-		record.AddAttrs(slog.Any(ResponseKey, &jsonpbMarshalleble{p}))
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get("user-agent"); len(v) > 0 {
+			record.AddAttrs(slog.String(UserAgentKey, v[0]))
+		}
+		if v := md.Get("referer"); len(v) > 0 {
+			record.AddAttrs(slog.String(RefererKey, v[0]))
+		}
 	}
 
 	return record
@@ -108,6 +217,9 @@ func newRPCRecord(ctx context.Context, t time.Time, path string, pbMsg interface
 // interface for protobuf message types.
 type jsonpbMarshalleble struct {
 	proto.Message
+
+	// redactor, if set, replaces sensitive values within the payload.
+	redactor *redactor
 }
 
 // MarshalJSON handles generating a slice of bytes representing the protobuf
@@ -119,5 +231,9 @@ func (j *jsonpbMarshalleble) MarshalJSON() ([]byte, error) {
 		return nil, fmt.Errorf("failed to marshal jsonpb: %s", err)
 	}
 
+	if j.redactor != nil {
+		return j.redactor.redactProto(j.Message, b.Bytes()), nil
+	}
+
 	return b.Bytes(), nil
 }
@@ -0,0 +1,182 @@
+package logging
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// ErrUnknownLogger is returned when a LevelController has no logger with the
+// given name.
+var ErrUnknownLogger = errors.New("logging: unknown logger")
+
+// DefaultLevelDuration is how long a level changed without a duration applies
+// before the logger reverts to its default level.
+const DefaultLevelDuration = 15 * time.Minute
+
+// LoggerLevel describes the level of a logger registered with a
+// LevelController.
+type LoggerLevel struct {
+	// Name is the name the logger was registered with.
+	Name string
+	// Level is the current level of the logger.
+	Level slog.Level
+	// DefaultLevel is the level the logger was registered with, which it
+	// reverts to when a temporary level expires.
+	DefaultLevel slog.Level
+	// ExpireTime is the time the current level reverts to the default level.
+	// It is the zero time if the logger is at its default level.
+	ExpireTime time.Time
+}
+
+// LevelController controls the level of named loggers at runtime. Each logger
+// is backed by a *slog.LevelVar which should be passed to the constructor of
+// its handler, for example
+//
+//	levels := logging.NewLevelController()
+//	h := logging.NewGoogleCloudHandler(os.Stdout, levels.Register("api", logging.LevelInfo))
+//
+// The levels may then be changed using the HTTP handler returned by
+// NewLevelHandler, or the gRPC service returned by NewLevelService.
+type LevelController struct {
+	mu     sync.Mutex
+	levels map[string]*controlledLevel
+}
+
+type controlledLevel struct {
+	level        slog.LevelVar
+	defaultLevel slog.Level
+	expireTime   time.Time
+	timer        *time.Timer
+}
+
+// NewLevelController returns a new LevelController without any loggers.
+func NewLevelController() *LevelController {
+	return &LevelController{
+		levels: make(map[string]*controlledLevel),
+	}
+}
+
+// Register returns the level of the logger with the given name, registering
+// the logger with the given default level if it does not already exist.
+func (c *LevelController) Register(name string, level slog.Level) *slog.LevelVar {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if l, ok := c.levels[name]; ok {
+		return &l.level
+	}
+
+	l := &controlledLevel{defaultLevel: level}
+	l.level.Set(level)
+	c.levels[name] = l
+
+	return &l.level
+}
+
+// Level returns the level of the logger with the given name.
+func (c *LevelController) Level(name string) (LoggerLevel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.levels[name]
+	if !ok {
+		return LoggerLevel{}, ErrUnknownLogger
+	}
+
+	return l.describe(name), nil
+}
+
+// Levels returns the levels of every registered logger, ordered by name.
+func (c *LevelController) Levels() []LoggerLevel {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	levels := make([]LoggerLevel, 0, len(c.levels))
+	for name, l := range c.levels {
+		levels = append(levels, l.describe(name))
+	}
+
+	sort.Slice(levels, func(i, j int) bool {
+		return levels[i].Name < levels[j].Name
+	})
+
+	return levels
+}
+
+// SetLevel changes the level of the logger with the given name until d has
+// elapsed, after which the logger reverts to its default level. If d is not
+// positive DefaultLevelDuration is used, so that a verbose level is never
+// left in place indefinitely.
+func (c *LevelController) SetLevel(name string, level slog.Level, d time.Duration) (LoggerLevel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.levels[name]
+	if !ok {
+		return LoggerLevel{}, ErrUnknownLogger
+	}
+
+	if d <= 0 {
+		d = DefaultLevelDuration
+	}
+
+	l.stop()
+	l.level.Set(level)
+	l.expireTime = time.Now().Add(d)
+
+	// The timer is compared on expiry so that a level set after the timer
+	// fired, but before it acquired the lock, is not reverted.
+	var timer *time.Timer
+	timer = time.AfterFunc(d, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if l.timer == timer {
+			l.reset()
+		}
+	})
+	l.timer = timer
+
+	return l.describe(name), nil
+}
+
+// ResetLevel restores the default level of the logger with the given name.
+func (c *LevelController) ResetLevel(name string) (LoggerLevel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.levels[name]
+	if !ok {
+		return LoggerLevel{}, ErrUnknownLogger
+	}
+
+	l.reset()
+
+	return l.describe(name), nil
+}
+
+func (l *controlledLevel) describe(name string) LoggerLevel {
+	return LoggerLevel{
+		Name:         name,
+		Level:        l.level.Level(),
+		DefaultLevel: l.defaultLevel,
+		ExpireTime:   l.expireTime,
+	}
+}
+
+func (l *controlledLevel) reset() {
+	l.stop()
+	l.level.Set(l.defaultLevel)
+}
+
+func (l *controlledLevel) stop() {
+	if l.timer != nil {
+		l.timer.Stop()
+		l.timer = nil
+	}
+	l.expireTime = time.Time{}
+}
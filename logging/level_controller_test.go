@@ -0,0 +1,315 @@
+package logging_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/kapetndev/connect/logging"
+	adminpb "github.com/kapetndev/connect/logging/admin/v1"
+	"github.com/kapetndev/grpctest"
+)
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parses the names of the custom and slog levels", func(t *testing.T) {
+		for s, expected := range map[string]slog.Level{
+			"trace":    logging.LevelTrace,
+			"DEBUG":    logging.LevelDebug,
+			"Notice":   logging.LevelNotice,
+			"WARNING":  logging.LevelWarning,
+			"WARN":     logging.LevelWarning,
+			"CRITICAL": logging.LevelCritical,
+			"INFO+1":   logging.LevelInfo + 1,
+		} {
+			level, err := logging.ParseLevel(s)
+			if err != nil {
+				t.Fatalf("error was not <nil>: %s", err)
+			}
+
+			if level != expected {
+				t.Errorf("levels are not equal: %s != %s", level, expected)
+			}
+		}
+	})
+
+	t.Run("returns an error when the level is unknown", func(t *testing.T) {
+		if _, err := logging.ParseLevel("LOUD"); err == nil {
+			t.Error("error was <nil>")
+		}
+	})
+}
+
+func TestLevelController(t *testing.T) {
+	t.Parallel()
+
+	t.Run("changes the level of a registered logger", func(t *testing.T) {
+		c := logging.NewLevelController()
+		level := c.Register("api", logging.LevelInfo)
+
+		if _, err := c.SetLevel("api", logging.LevelTrace, 0); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		if level.Level() != logging.LevelTrace {
+			t.Errorf("levels are not equal: %s != %s", level.Level(), logging.LevelTrace)
+		}
+	})
+
+	t.Run("returns the existing level when a logger is registered again", func(t *testing.T) {
+		c := logging.NewLevelController()
+
+		if c.Register("api", logging.LevelInfo) != c.Register("api", logging.LevelDebug) {
+			t.Error("levels are not the same")
+		}
+	})
+
+	t.Run("reverts to the default level once the duration has elapsed", func(t *testing.T) {
+		c := logging.NewLevelController()
+		level := c.Register("api", logging.LevelInfo)
+
+		l, err := c.SetLevel("api", logging.LevelDebug, 10*time.Millisecond)
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		if l.ExpireTime.IsZero() {
+			t.Error("expire time was not set")
+		}
+
+		deadline := time.Now().Add(time.Second)
+		for level.Level() != logging.LevelInfo && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+
+		if level.Level() != logging.LevelInfo {
+			t.Errorf("levels are not equal: %s != %s", level.Level(), logging.LevelInfo)
+		}
+
+		if l, _ := c.Level("api"); !l.ExpireTime.IsZero() {
+			t.Errorf("expire time was not reset: %s", l.ExpireTime)
+		}
+	})
+
+	t.Run("reverts after the default duration when none is given", func(t *testing.T) {
+		c := logging.NewLevelController()
+		c.Register("api", logging.LevelInfo)
+
+		before := time.Now()
+		l, err := c.SetLevel("api", logging.LevelDebug, 0)
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		if l.ExpireTime.Before(before.Add(logging.DefaultLevelDuration)) || l.ExpireTime.After(time.Now().Add(logging.DefaultLevelDuration)) {
+			t.Errorf("expire time is not after the default duration: %s", l.ExpireTime)
+		}
+	})
+
+	t.Run("does not revert a level set after a temporary level", func(t *testing.T) {
+		c := logging.NewLevelController()
+		level := c.Register("api", logging.LevelInfo)
+		sentinel := c.Register("sentinel", logging.LevelInfo)
+
+		_, _ = c.SetLevel("api", logging.LevelDebug, 10*time.Millisecond)
+		_, _ = c.SetLevel("api", logging.LevelTrace, 0)
+
+		// The sentinel reverts once a level set at the same time as the
+		// temporary level would have expired.
+		_, _ = c.SetLevel("sentinel", logging.LevelDebug, 10*time.Millisecond)
+
+		deadline := time.Now().Add(time.Second)
+		for sentinel.Level() != logging.LevelInfo && time.Now().Before(deadline) {
+			if level.Level() != logging.LevelTrace {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		if sentinel.Level() != logging.LevelInfo {
+			t.Fatalf("levels are not equal: %s != %s", sentinel.Level(), logging.LevelInfo)
+		}
+		if level.Level() != logging.LevelTrace {
+			t.Errorf("levels are not equal: %s != %s", level.Level(), logging.LevelTrace)
+		}
+	})
+
+	t.Run("returns an error when the logger is unknown", func(t *testing.T) {
+		c := logging.NewLevelController()
+
+		if _, err := c.SetLevel("api", logging.LevelDebug, 0); err != logging.ErrUnknownLogger {
+			t.Errorf("errors are not equal: %v != %s", err, logging.ErrUnknownLogger)
+		}
+	})
+
+	t.Run("controls the level of a handler", func(t *testing.T) {
+		c := logging.NewLevelController()
+		h := logging.NewGoogleCloudHandler(&strings.Builder{}, c.Register("api", logging.LevelInfo))
+
+		if h.Enabled(context.Background(), logging.LevelDebug) {
+			t.Error("handler was enabled at the debug level")
+		}
+
+		_, _ = c.SetLevel("api", logging.LevelDebug, 0)
+
+		if !h.Enabled(context.Background(), logging.LevelDebug) {
+			t.Error("handler was not enabled at the debug level")
+		}
+	})
+}
+
+func TestNewLevelHandler(t *testing.T) {
+	t.Parallel()
+
+	serve := func(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	t.Run("returns the levels of every logger", func(t *testing.T) {
+		c := logging.NewLevelController()
+		c.Register("worker", logging.LevelNotice)
+		c.Register("api", logging.LevelInfo)
+
+		w := serve(logging.NewLevelHandler(c), http.MethodGet, "/", "")
+
+		expected := `{"levels":[{"name":"api","level":"INFO","defaultLevel":"INFO"},{"name":"worker","level":"NOTICE","defaultLevel":"NOTICE"}]}`
+		if body := strings.TrimSpace(w.Body.String()); body != expected {
+			t.Errorf("bodies are not equal: %s != %s", body, expected)
+		}
+	})
+
+	t.Run("changes the level of a logger for a duration", func(t *testing.T) {
+		c := logging.NewLevelController()
+		level := c.Register("api", logging.LevelInfo)
+
+		w := serve(logging.NewLevelHandler(c), http.MethodPut, "/", `{"name":"api","level":"trace","duration":"15m"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status codes are not equal: %d != %d", w.Code, http.StatusOK)
+		}
+
+		var resp map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+
+		if resp["level"] != "TRACE" {
+			t.Errorf("levels are not equal: %v != %s", resp["level"], "TRACE")
+		}
+
+		if _, ok := resp["expireTime"]; !ok {
+			t.Error("expire time was not written")
+		}
+
+		if level.Level() != logging.LevelTrace {
+			t.Errorf("levels are not equal: %s != %s", level.Level(), logging.LevelTrace)
+		}
+	})
+
+	t.Run("returns an error status for invalid requests", func(t *testing.T) {
+		c := logging.NewLevelController()
+		c.Register("api", logging.LevelInfo)
+		h := logging.NewLevelHandler(c)
+
+		for _, tt := range []struct {
+			method, target, body string
+			expected             int
+		}{
+			{http.MethodGet, "/?name=worker", "", http.StatusNotFound},
+			{http.MethodPut, "/", `{"name":"worker","level":"DEBUG"}`, http.StatusNotFound},
+			{http.MethodPut, "/", `{"name":"api","level":"LOUD"}`, http.StatusBadRequest},
+			{http.MethodPut, "/", `{"name":"api","level":"DEBUG","duration":"soon"}`, http.StatusBadRequest},
+			{http.MethodDelete, "/", "", http.StatusMethodNotAllowed},
+		} {
+			if w := serve(h, tt.method, tt.target, tt.body); w.Code != tt.expected {
+				t.Errorf("status codes are not equal for %s %s: %d != %d", tt.method, tt.target, w.Code, tt.expected)
+			}
+		}
+	})
+}
+
+func setupLevelServer(t *testing.T, c *logging.LevelController) (grpctest.Closer, adminpb.LevelServiceClient) {
+	s := grpctest.NewServer()
+
+	conn, err := s.ClientConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adminpb.RegisterLevelServiceServer(s, logging.NewLevelService(c))
+	s.Serve()
+
+	return s.Close, adminpb.NewLevelServiceClient(conn)
+}
+
+func TestNewLevelService(t *testing.T) {
+	t.Parallel()
+
+	t.Run("changes the level of a logger for a duration", func(t *testing.T) {
+		c := logging.NewLevelController()
+		level := c.Register("api", logging.LevelInfo)
+
+		closer, client := setupLevelServer(t, c)
+		defer closer()
+
+		resp, err := client.SetLevel(context.Background(), &adminpb.SetLevelRequest{
+			Name:     "api",
+			Level:    "DEBUG",
+			Duration: durationpb.New(15 * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		if resp.Level != "DEBUG" || resp.DefaultLevel != "INFO" {
+			t.Errorf("levels are not equal: %s, %s != DEBUG, INFO", resp.Level, resp.DefaultLevel)
+		}
+
+		if resp.ExpireTime == nil {
+			t.Error("expire time was <nil>")
+		}
+
+		if level.Level() != logging.LevelDebug {
+			t.Errorf("levels are not equal: %s != %s", level.Level(), logging.LevelDebug)
+		}
+	})
+
+	t.Run("restores the default level when the level is empty", func(t *testing.T) {
+		c := logging.NewLevelController()
+		level := c.Register("api", logging.LevelInfo)
+		_, _ = c.SetLevel("api", logging.LevelTrace, 0)
+
+		closer, client := setupLevelServer(t, c)
+		defer closer()
+
+		if _, err := client.SetLevel(context.Background(), &adminpb.SetLevelRequest{Name: "api"}); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		if level.Level() != logging.LevelInfo {
+			t.Errorf("levels are not equal: %s != %s", level.Level(), logging.LevelInfo)
+		}
+	})
+
+	t.Run("returns a not found error when the logger is unknown", func(t *testing.T) {
+		closer, client := setupLevelServer(t, logging.NewLevelController())
+		defer closer()
+
+		_, err := client.GetLevel(context.Background(), &adminpb.GetLevelRequest{Name: "api"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("codes are not equal: %s != %s", status.Code(err), codes.NotFound)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// loggerLevelJSON is the representation of a LoggerLevel used by the HTTP
+// handler.
+type loggerLevelJSON struct {
+	Name         string     `json:"name"`
+	Level        string     `json:"level"`
+	DefaultLevel string     `json:"defaultLevel"`
+	ExpireTime   *time.Time `json:"expireTime,omitempty"`
+}
+
+// setLevelJSON is the body of a request to change the level of a logger.
+type setLevelJSON struct {
+	Name     string `json:"name"`
+	Level    string `json:"level"`
+	Duration string `json:"duration"`
+}
+
+// NewLevelHandler returns a HTTP handler reading and changing the levels of
+// the loggers registered with c.
+//
+// A GET request returns the levels of every logger, or of a single logger if
+// the name query parameter is set. A PUT request changes the level of a
+// logger, for example
+//
+//	{"name": "api", "level": "DEBUG", "duration": "15m"}
+//
+// where the optional duration is parsed by time.ParseDuration, after which the
+// logger reverts to its default level. Without a duration the logger reverts
+// after DefaultLevelDuration. An empty level restores the default level
+// immediately.
+func NewLevelHandler(c *LevelController) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			if name := r.URL.Query().Get("name"); name != "" {
+				l, err := c.Level(name)
+				if err != nil {
+					writeLevelError(w, err)
+					return
+				}
+
+				writeLevelJSON(w, newLoggerLevelJSON(l))
+				return
+			}
+
+			levels := c.Levels()
+
+			resp := struct {
+				Levels []loggerLevelJSON `json:"levels"`
+			}{make([]loggerLevelJSON, 0, len(levels))}
+
+			for _, l := range levels {
+				resp.Levels = append(resp.Levels, newLoggerLevelJSON(l))
+			}
+
+			writeLevelJSON(w, resp)
+		case http.MethodPut:
+			var req setLevelJSON
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "invalid request body", http.StatusBadRequest)
+				return
+			}
+
+			var d time.Duration
+			if req.Duration != "" {
+				var err error
+				if d, err = time.ParseDuration(req.Duration); err != nil {
+					writeLevelError(w, errInvalidLevel)
+					return
+				}
+			}
+
+			l, err := setLevel(c, req.Name, req.Level, d)
+			if err != nil {
+				writeLevelError(w, err)
+				return
+			}
+
+			writeLevelJSON(w, newLoggerLevelJSON(l))
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// errInvalidLevel is returned when the level or duration of a request to
+// change the level of a logger cannot be parsed.
+var errInvalidLevel = errors.New("logging: invalid level or duration")
+
+// setLevel changes the level of a logger on behalf of the HTTP handler and
+// gRPC service, restoring the default level if level is empty.
+func setLevel(c *LevelController, name, level string, d time.Duration) (LoggerLevel, error) {
+	if level == "" {
+		return c.ResetLevel(name)
+	}
+
+	l, err := ParseLevel(level)
+	if err != nil || d < 0 {
+		return LoggerLevel{}, errInvalidLevel
+	}
+
+	return c.SetLevel(name, l, d)
+}
+
+func newLoggerLevelJSON(l LoggerLevel) loggerLevelJSON {
+	v := loggerLevelJSON{
+		Name:         l.Name,
+		Level:        levelName(l.Level),
+		DefaultLevel: levelName(l.DefaultLevel),
+	}
+
+	if !l.ExpireTime.IsZero() {
+		v.ExpireTime = &l.ExpireTime
+	}
+
+	return v
+}
+
+func writeLevelJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeLevelError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownLogger):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	adminpb "github.com/kapetndev/connect/logging/admin/v1"
+)
+
+type levelService struct {
+	adminpb.UnimplementedLevelServiceServer
+	controller *LevelController
+}
+
+// NewLevelService returns a gRPC service reading and changing the levels of
+// the loggers registered with c. It should be registered with a server using
+// adminpb.RegisterLevelServiceServer.
+func NewLevelService(c *LevelController) adminpb.LevelServiceServer {
+	return &levelService{controller: c}
+}
+
+func (s *levelService) ListLevels(ctx context.Context, req *adminpb.ListLevelsRequest) (*adminpb.ListLevelsResponse, error) {
+	levels := s.controller.Levels()
+
+	resp := &adminpb.ListLevelsResponse{
+		Levels: make([]*adminpb.LoggerLevel, 0, len(levels)),
+	}
+
+	for _, l := range levels {
+		resp.Levels = append(resp.Levels, newLoggerLevelProto(l))
+	}
+
+	return resp, nil
+}
+
+func (s *levelService) GetLevel(ctx context.Context, req *adminpb.GetLevelRequest) (*adminpb.LoggerLevel, error) {
+	l, err := s.controller.Level(req.GetName())
+	if err != nil {
+		return nil, levelStatusError(err)
+	}
+
+	return newLoggerLevelProto(l), nil
+}
+
+func (s *levelService) SetLevel(ctx context.Context, req *adminpb.SetLevelRequest) (*adminpb.LoggerLevel, error) {
+	if d := req.GetDuration(); d != nil && d.CheckValid() != nil {
+		return nil, levelStatusError(errInvalidLevel)
+	}
+
+	l, err := setLevel(s.controller, req.GetName(), req.GetLevel(), req.GetDuration().AsDuration())
+	if err != nil {
+		return nil, levelStatusError(err)
+	}
+
+	return newLoggerLevelProto(l), nil
+}
+
+func newLoggerLevelProto(l LoggerLevel) *adminpb.LoggerLevel {
+	v := &adminpb.LoggerLevel{
+		Name:         l.Name,
+		Level:        levelName(l.Level),
+		DefaultLevel: levelName(l.DefaultLevel),
+	}
+
+	if !l.ExpireTime.IsZero() {
+		v.ExpireTime = timestamppb.New(l.ExpireTime)
+	}
+
+	return v
+}
+
+func levelStatusError(err error) error {
+	switch {
+	case errors.Is(err, ErrUnknownLogger):
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc/codes"
+)
+
+// CodeLevelFunc determines the level of a log entry from the gRPC status code
+// returned by a call.
+type CodeLevelFunc func(codes.Code) slog.Level
+
+// StatusLevelFunc determines the level of a log entry from the HTTP status
+// code returned by a request.
+type StatusLevelFunc func(int) slog.Level
+
+// DefaultCodeLevel is the default mapping of gRPC status codes to levels.
+// Codes indicating the client is at fault are logged as warnings, whereas
+// codes indicating a server failure are logged as errors.
+func DefaultCodeLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return LevelInfo
+	case codes.Canceled,
+		codes.InvalidArgument,
+		codes.DeadlineExceeded,
+		codes.NotFound,
+		codes.AlreadyExists,
+		codes.PermissionDenied,
+		codes.ResourceExhausted,
+		codes.FailedPrecondition,
+		codes.Aborted,
+		codes.OutOfRange,
+		codes.Unauthenticated:
+		return LevelWarning
+	default:
+		// Unknown, Unimplemented, Internal, Unavailable, DataLoss and any code
+		// not known at the time of writing.
+		return LevelError
+	}
+}
+
+// DefaultStatusLevel is the default mapping of HTTP status codes to levels.
+// Client errors (4xx) are logged as warnings and server errors (5xx) are
+// logged as errors.
+func DefaultStatusLevel(statusCode int) slog.Level {
+	switch {
+	case statusCode >= http.StatusInternalServerError:
+		return LevelError
+	case statusCode >= http.StatusBadRequest:
+		return LevelWarning
+	default:
+		return LevelInfo
+	}
+}
+
+// ParseLevel parses the name of a level, ignoring case. In addition to the
+// names understood by slog, such as "DEBUG" or "WARN+1", it accepts the names
+// of the levels defined by this package, such as "NOTICE" and "CRITICAL".
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "TRACE":
+		return LevelTrace, nil
+	case "NOTICE":
+		return LevelNotice, nil
+	case "WARNING":
+		return LevelWarning, nil
+	case "EMERGENCY":
+		return LevelEmergency, nil
+	case "ALERT":
+		return LevelAlert, nil
+	case "CRITICAL":
+		return LevelCritical, nil
+	}
+
+	var level slog.Level
+	err := level.UnmarshalText([]byte(s))
+	return level, err
+}
+
+// enabled reports whether a record at level is handled by a handler whose
+// minimum level is given by leveler, which defaults to LevelInfo when nil.
+func enabled(leveler slog.Leveler, level slog.Level) bool {
+	minLevel := LevelInfo
+	if leveler != nil {
+		minLevel = leveler.Level()
+	}
+	return level >= minLevel
+}
+
+// levelName returns the name of a level as written by the handlers of this
+// package. Levels between those defined by this package are named relative to
+// a slog level, such as "INFO+1", so that they may be parsed by ParseLevel.
+func levelName(level slog.Level) string {
+	name := severityValue(slog.AnyValue(level)).String()
+	if l, err := ParseLevel(name); err == nil && l == level {
+		return name
+	}
+	return level.String()
+}
@@ -0,0 +1,173 @@
+package logging_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kapetndev/connect/logging"
+	"github.com/kapetndev/connect/logging/logtest"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+)
+
+func TestDefaultCodeLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := map[codes.Code]slog.Level{
+		codes.OK:                 logging.LevelInfo,
+		codes.Canceled:           logging.LevelWarning,
+		codes.Unknown:            logging.LevelError,
+		codes.InvalidArgument:    logging.LevelWarning,
+		codes.DeadlineExceeded:   logging.LevelWarning,
+		codes.NotFound:           logging.LevelWarning,
+		codes.AlreadyExists:      logging.LevelWarning,
+		codes.PermissionDenied:   logging.LevelWarning,
+		codes.ResourceExhausted:  logging.LevelWarning,
+		codes.FailedPrecondition: logging.LevelWarning,
+		codes.Aborted:            logging.LevelWarning,
+		codes.OutOfRange:         logging.LevelWarning,
+		codes.Unimplemented:      logging.LevelError,
+		codes.Internal:           logging.LevelError,
+		codes.Unavailable:        logging.LevelError,
+		codes.DataLoss:           logging.LevelError,
+		codes.Unauthenticated:    logging.LevelWarning,
+		codes.Code(100):          logging.LevelError,
+	}
+
+	for code, want := range tests {
+		if got := logging.DefaultCodeLevel(code); got != want {
+			t.Errorf("levels are not equal: %s: %s != %s", code, got, want)
+		}
+	}
+}
+
+func TestDefaultStatusLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		want   slog.Level
+	}{
+		{name: "informational", status: http.StatusSwitchingProtocols, want: logging.LevelInfo},
+		{name: "successful", status: http.StatusOK, want: logging.LevelInfo},
+		{name: "redirection", status: http.StatusFound, want: logging.LevelInfo},
+		{name: "last redirection", status: 399, want: logging.LevelInfo},
+		{name: "client error", status: http.StatusBadRequest, want: logging.LevelWarning},
+		{name: "last client error", status: 499, want: logging.LevelWarning},
+		{name: "server error", status: http.StatusInternalServerError, want: logging.LevelError},
+		{name: "last server error", status: 599, want: logging.LevelError},
+	}
+
+	for _, tt := range tests {
+		if got := logging.DefaultStatusLevel(tt.status); got != tt.want {
+			t.Errorf("levels are not equal: %s: %s != %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithCodeLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		opts    []logging.Option
+		message string
+		code    codes.Code
+		want    slog.Level
+	}{
+		{
+			name:    "uses the default level of a successful call",
+			message: "engage",
+			want:    logging.LevelInfo,
+		},
+		{
+			name:    "uses the default level of a failed call",
+			message: "InvalidArgument",
+			code:    codes.InvalidArgument,
+			want:    logging.LevelWarning,
+		},
+		{
+			name:    "uses the configured level of a failed call",
+			message: "InvalidArgument",
+			code:    codes.InvalidArgument,
+			opts: []logging.Option{logging.WithCodeLevels(func(code codes.Code) slog.Level {
+				if code == codes.InvalidArgument {
+					return logging.LevelError
+				}
+				return logging.DefaultCodeLevel(code)
+			})},
+			want: logging.LevelError,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			closer, client, h := logtest.NewServer(t, nil, tt.opts...)
+			defer closer()
+
+			// A message naming a status code fails with that code.
+			_, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: tt.message})
+			if status.Code(err) != tt.code {
+				t.Fatalf("error codes are not equal: %s != %s", status.Code(err), tt.code)
+			}
+
+			logtest.AssertLogged(t, h, logtest.Path("/echo.v1.EchoService/Echo"), logtest.Level(tt.want))
+		})
+	}
+}
+
+func TestWithStatusLevels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		opts   []logging.Option
+		status int
+		want   slog.Level
+	}{
+		{
+			name:   "uses the default level of a successful request",
+			status: http.StatusOK,
+			want:   logging.LevelInfo,
+		},
+		{
+			name:   "uses the default level of a failed request",
+			status: http.StatusNotFound,
+			want:   logging.LevelWarning,
+		},
+		{
+			name: "uses the configured level of a failed request",
+			opts: []logging.Option{logging.WithStatusLevels(func(status int) slog.Level {
+				if status == http.StatusNotFound {
+					return logging.LevelInfo
+				}
+				return logging.DefaultStatusLevel(status)
+			})},
+			status: http.StatusNotFound,
+			want:   logging.LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, h := logtest.ServeHTTP(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}, httptest.NewRequest(http.MethodGet, "/bridge", nil), tt.opts...)
+
+			logtest.AssertLogged(t, h, logtest.Status(tt.status), logtest.Level(tt.want))
+		})
+	}
+}
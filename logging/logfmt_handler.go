@@ -0,0 +1,196 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/exp/slog"
+)
+
+// LogfmtHandler is a handler that formats log messages as logfmt, a sequence
+// of key=value pairs on a single line.
+//
+// Levels are written using the names of the custom levels, such as NOTICE and
+// CRITICAL, and the attributes of groups are written with their keys qualified
+// by the group name, such as group.key=value. Values implementing
+// json.Marshaler, including request and response payloads, are written as
+// compact JSON.
+type LogfmtHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	level slog.Leveler
+
+	// State accumulated by WithAttrs and WithGroup.
+	groups handlerGroups
+}
+
+// NewLogfmtHandler returns a new LogfmtHandler.
+func NewLogfmtHandler(w io.Writer, level slog.Leveler) *LogfmtHandler {
+	return &LogfmtHandler{
+		w:     w,
+		mu:    &sync.Mutex{},
+		level: level,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level. The
+// handler ignores records whose level is lower.
+func (h *LogfmtHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return enabled(h.level, level)
+}
+
+// Handle formats its argument Record as logfmt on a single line.
+func (h *LogfmtHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+
+	if !r.Time.IsZero() {
+		appendLogfmtPair(&buf, slog.TimeKey, slog.TimeValue(r.Time))
+	}
+	appendLogfmtPair(&buf, slog.LevelKey, severityValue(slog.AnyValue(r.Level)))
+	appendLogfmtPair(&buf, slog.MessageKey, slog.StringValue(r.Message))
+
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) {
+		attrs = append(attrs, a)
+	})
+
+	for _, a := range h.groups.nest(attrs) {
+		appendLogfmtAttr(&buf, "", a)
+	}
+
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+// WithAttrs returns a new LogfmtHandler whose attributes consists of h's
+// attributes followed by attrs.
+func (h *LogfmtHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.groups = h.groups.withAttrs(attrs)
+	return &h2
+}
+
+// WithGroup returns a new LogfmtHandler whose attributes consists of h's
+// attributes followed by a group with the given name.
+func (h *LogfmtHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	h2.groups = h.groups.withGroup(name)
+	return &h2
+}
+
+// appendLogfmtAttr appends a to buf as a key=value pair. The attributes of a
+// group are appended individually with their keys qualified by the group
+// name. Attributes with an empty key are ignored, except for groups whose
+// attributes are inlined.
+func appendLogfmtAttr(buf *bytes.Buffer, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	key := a.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		key = prefix
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		for _, ga := range a.Value.Group() {
+			appendLogfmtAttr(buf, key, ga)
+		}
+		return
+	}
+
+	if a.Key == "" {
+		return
+	}
+
+	appendLogfmtPair(buf, key, a.Value)
+}
+
+// appendLogfmtPair appends a single key=value pair to buf, separated from any
+// preceding pair by a space.
+func appendLogfmtPair(buf *bytes.Buffer, key string, v slog.Value) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+
+	buf.WriteString(logfmtKey(key))
+	buf.WriteByte('=')
+	buf.WriteString(logfmtValue(v))
+}
+
+// logfmtKey returns key with any characters not permitted in a logfmt key
+// replaced by an underscore.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// logfmtValue formats v as a logfmt value, quoting it where necessary.
+func logfmtValue(v slog.Value) string {
+	var s string
+
+	switch v.Kind() {
+	case slog.KindString:
+		s = v.String()
+	case slog.KindTime:
+		s = v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case json.Marshaler:
+			b, err := json.Marshal(x)
+			if err != nil {
+				s = err.Error()
+				break
+			}
+			s = string(b)
+		case error:
+			s = x.Error()
+		default:
+			s = v.String()
+		}
+	default:
+		s = v.String()
+	}
+
+	if needsLogfmtQuoting(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// needsLogfmtQuoting reports whether s must be quoted to be read back as a
+// single logfmt value.
+func needsLogfmtQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+
+	return false
+}
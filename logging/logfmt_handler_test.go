@@ -0,0 +1,128 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc"
+
+	"github.com/kapetndev/connect/logging"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+)
+
+func TestLogfmtHandler(t *testing.T) {
+	t.Parallel()
+
+	handle := func(t *testing.T, h slog.Handler, level slog.Level, msg string, attrs ...slog.Attr) {
+		r := slog.NewRecord(time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC), level, msg, 0)
+		r.AddAttrs(attrs...)
+
+		if err := h.Handle(context.Background(), r); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		level slog.Level
+		msg   string
+		attrs []slog.Attr
+		want  string
+	}{
+		{
+			name:  "writes the names of custom levels",
+			level: logging.LevelNotice,
+			msg:   "hailing",
+			want:  `time=2023-04-01T12:00:00Z level=NOTICE msg=hailing`,
+		},
+		{
+			name:  "quotes and escapes values",
+			level: logging.LevelInfo,
+			msg:   "engage",
+			attrs: []slog.Attr{
+				slog.String("ship", "USS Enterprise"),
+				slog.String("quote", `make it "so"`),
+				slog.String("log", "line one\nline two"),
+				slog.String("empty", ""),
+				slog.String("eq", "a=b"),
+				slog.Any("error", errors.New("warp core breach")),
+			},
+			want: `time=2023-04-01T12:00:00Z level=INFO msg=engage ship="USS Enterprise" quote="make it \"so\"" log="line one\nline two" empty="" eq="a=b" error="warp core breach"`,
+		},
+		{
+			name:  "flattens groups into dotted keys",
+			level: logging.LevelInfo,
+			msg:   "engage",
+			attrs: []slog.Attr{
+				slog.Group("ship", slog.String("name", "enterprise"), slog.Group("warp", slog.Int("factor", 9))),
+			},
+			want: `time=2023-04-01T12:00:00Z level=INFO msg=engage ship.name=enterprise ship.warp.factor=9`,
+		},
+		{
+			name:  "replaces characters not permitted in keys",
+			level: logging.LevelInfo,
+			msg:   "engage",
+			attrs: []slog.Attr{slog.String("first officer", "riker")},
+			want:  `time=2023-04-01T12:00:00Z level=INFO msg=engage first_officer=riker`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			handle(t, logging.NewLogfmtHandler(buf, logging.LevelInfo), tt.level, tt.msg, tt.attrs...)
+
+			if got := strings.TrimSuffix(buf.String(), "\n"); got != tt.want {
+				t.Errorf("lines are not equal: %s != %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("writes payloads as compact quoted JSON", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		logger := logging.New(logging.NewLogfmtHandler(buf, logging.LevelInfo))
+		logger.Info(context.Background(), "engage", slog.Any(logging.RequestKey, json.RawMessage(`{ "captain": "picard" }`)))
+
+		want := `msg=engage requestPayload="{\"captain\":\"picard\"}"`
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("line does not contain payload: %s", buf.String())
+		}
+	})
+
+	t.Run("writes attributes and groups added to the handler", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		logger := logging.New(logging.NewLogfmtHandler(buf, logging.LevelInfo)).With("captain", "picard").WithGroup("ship")
+		logger.Info(context.Background(), "engage", "registry", "NCC-1701-D")
+
+		want := `msg=engage captain=picard ship.registry=NCC-1701-D`
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("line does not contain attributes: %s", buf.String())
+		}
+	})
+
+	t.Run("writes proto payloads logged by the interceptors as JSON", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		interceptor := logging.UnaryServerInterceptor(logging.WithHandler(logging.NewLogfmtHandler(buf, logging.LevelInfo)))
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/echo.v1.EchoService/Echo"}
+		_, _ = interceptor(context.Background(), &echopb.EchoRequest{Message: "engage"}, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &echopb.EchoResponse{Message: "make it so"}, nil
+		})
+
+		want := `jsonPayload="{\"message\":\"make it so\"}"`
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("line does not contain payload: %s", buf.String())
+		}
+	})
+}
@@ -3,9 +3,14 @@ package logging
 import (
 	"context"
 	"os"
+	"runtime"
+	"strings"
 	"sync/atomic"
+	"time"
 
 	"golang.org/x/exp/slog"
+
+	"github.com/kapetndev/connect/requestid"
 )
 
 // Level denotes the severity of a log entry.
@@ -23,12 +28,24 @@ const (
 
 // Extended logger attribute keys.
 const (
-	DeadlineKey = "deadline"
-	DurationKey = "duration"
-	MethodKey   = "method"
-	PathKey     = "path"
-	ResponseKey = "jsonPayload"
-	StatusKey   = "status"
+	CodeKey         = "code"
+	DeadlineKey     = "deadline"
+	DurationKey     = "duration"
+	KindKey         = "kind"
+	MethodKey       = "method"
+	PathKey         = "path"
+	ProtocolKey     = "protocol"
+	RefererKey      = "referer"
+	RemoteIPKey     = "remoteIp"
+	RequestIDKey    = "requestId"
+	RequestKey      = "requestPayload"
+	RequestSizeKey  = "requestSize"
+	ResponseKey     = "jsonPayload"
+	ResponseSizeKey = "responseSize"
+	ServerIPKey     = "serverIp"
+	StatusKey       = "status"
+	URLKey          = "url"
+	UserAgentKey    = "userAgent"
 )
 
 // LeveledLogger is a logger that logs messages at a specific level.
@@ -59,6 +76,30 @@ func New(h slog.Handler) *LeveledLogger {
 	}
 }
 
+// With returns a new LeveledLogger that includes the given attributes in each
+// log entry. The arguments are interpreted as by slog.Logger.With.
+func (l *LeveledLogger) With(args ...any) *LeveledLogger {
+	return &LeveledLogger{
+		logger: l.logger.With(args...),
+	}
+}
+
+// WithAttrs returns a new LeveledLogger that includes the given attributes in
+// each log entry.
+func (l *LeveledLogger) WithAttrs(attrs ...slog.Attr) *LeveledLogger {
+	return &LeveledLogger{
+		logger: slog.New(l.logger.Handler().WithAttrs(attrs)),
+	}
+}
+
+// WithGroup returns a new LeveledLogger that starts a group. The attributes of
+// each subsequent log entry are qualified by the group name.
+func (l *LeveledLogger) WithGroup(name string) *LeveledLogger {
+	return &LeveledLogger{
+		logger: l.logger.WithGroup(name),
+	}
+}
+
 // Trace logs a message at the trace level.
 func (l *LeveledLogger) Trace(ctx context.Context, msg string, attrs ...any) {
 	l.log(ctx, LevelTrace, msg, attrs...)
@@ -104,13 +145,72 @@ func (l *LeveledLogger) Critical(ctx context.Context, msg string, attrs ...any)
 	l.log(ctx, LevelCritical, msg, attrs...)
 }
 
-// Log logs a message at the specified level.
+// Log logs a message at the specified level. The source of the record is the
+// caller of the exported method, such as Info, rather than this package.
 func (l *LeveledLogger) log(ctx context.Context, level slog.Level, msg string, attrs ...any) {
+	h := l.logger.Handler()
+	if !h.Enabled(ctx, level) {
+		return
+	}
+
 	// If a deadline was set on the context and it has been exceeded then add
 	// this to the log entry.
 	if d, ok := ctx.Deadline(); ok {
 		attrs = append(attrs, slog.Time(DeadlineKey, d))
 	}
 
-	l.logger.Log(ctx, level, msg, attrs...)
+	// If the request has been assigned an ID then add this to the log entry.
+	if id, ok := requestid.FromContext(ctx); ok {
+		attrs = append(attrs, slog.String(RequestIDKey, id))
+	}
+
+	// Skip runtime.Callers, this function and the exported method calling it.
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+
+	// The stack is taken where the logger was called, rather than by the
+	// handler, which may handle the record on another goroutine.
+	if level >= LevelError {
+		ctx = context.WithValue(ctx, callStackContextKey{}, callerStack())
+	}
+
+	r := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	r.Add(attrs...)
+	_ = h.Handle(ctx, r)
+}
+
+// callStackContextKey is the key of the stack of the call to a LeveledLogger
+// within the context passed to its handler.
+type callStackContextKey struct{}
+
+// callStackFromContext returns the stack of the call to the LeveledLogger
+// whose record is handled with ctx, if any. The stack is only taken for
+// records at the error level or above.
+func callStackFromContext(ctx context.Context) string {
+	stack, _ := ctx.Value(callStackContextKey{}).(string)
+	return stack
+}
+
+// callerStack returns the stack of the current goroutine in the format of
+// runtime.Stack, beginning with the function which called the logger.
+func callerStack() string {
+	buf := make([]byte, 4<<10)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	// Each frame is written as a line naming the function followed by a line
+	// holding its location. Skip those of this function, LeveledLogger.log
+	// and the exported method calling it, keeping the goroutine header.
+	lines := strings.SplitAfter(string(buf), "\n")
+	if len(lines) < 7 {
+		return string(buf)
+	}
+
+	return lines[0] + strings.Join(lines[7:], "")
 }
@@ -0,0 +1,183 @@
+package logtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+// Matcher reports whether a record matches some condition, and describes the
+// condition for use in failure messages.
+type Matcher struct {
+	match       func(Record) bool
+	description string
+}
+
+// String returns the description of the condition.
+func (m Matcher) String() string {
+	return m.description
+}
+
+// Level matches records logged at the given level.
+func Level(level slog.Level) Matcher {
+	return Matcher{
+		match:       func(r Record) bool { return r.Level == level },
+		description: fmt.Sprintf("level=%s", level),
+	}
+}
+
+// Message matches records with the given message.
+func Message(msg string) Matcher {
+	return Matcher{
+		match:       func(r Record) bool { return r.Message == msg },
+		description: fmt.Sprintf("message=%q", msg),
+	}
+}
+
+// Attr matches records with an attribute of the given key whose value is
+// equal to value. Values are compared by their JSON representation, so an int
+// matches an equal int64 or float64.
+func Attr(key string, value any) Matcher {
+	return AttrPath([]string{key}, value)
+}
+
+// AttrPath matches records with an attribute at the given path whose value is
+// equal to value. The path is the key of the attribute followed by those of
+// any nested groups and JSON objects, as passed to Record.Attr.
+func AttrPath(path []string, value any) Matcher {
+	return Matcher{
+		match: func(r Record) bool {
+			v, ok := r.Attr(path...)
+			return ok && jsonEqual(v, value)
+		},
+		description: fmt.Sprintf("%s=%v", strings.Join(path, "."), value),
+	}
+}
+
+// Method matches records of requests with the given method, which is POST
+// for every gRPC call.
+func Method(method string) Matcher {
+	return Attr(logging.MethodKey, method)
+}
+
+// Path matches records of requests with the given path, or gRPC calls to the
+// given full method name.
+func Path(path string) Matcher {
+	return Attr(logging.PathKey, path)
+}
+
+// Status matches records of HTTP requests with the given status code.
+func Status(code int) Matcher {
+	return Attr(logging.StatusKey, code)
+}
+
+// Code matches records of gRPC calls with the given status code.
+func Code(code codes.Code) Matcher {
+	return Attr(logging.CodeKey, code.String())
+}
+
+// RequestField matches records whose request payload has a field of the given
+// name equal to value. Nested fields are matched using AttrPath.
+func RequestField(name string, value any) Matcher {
+	return AttrPath([]string{logging.RequestKey, name}, value)
+}
+
+// ResponseField matches records whose response payload has a field of the
+// given name equal to value. Nested fields are matched using AttrPath.
+func ResponseField(name string, value any) Matcher {
+	return AttrPath([]string{logging.ResponseKey, name}, value)
+}
+
+// TB is the subset of testing.TB used to report failed assertions and
+// helpers.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// Find returns the first record captured by h matching every matcher.
+func Find(h *Handler, matchers ...Matcher) (Record, bool) {
+	for _, r := range h.Records() {
+		if matchAll(r, matchers) {
+			return r, true
+		}
+	}
+	return Record{}, false
+}
+
+// AssertLogged fails the test unless a record matching every matcher was
+// captured by h. It returns the first matching record.
+func AssertLogged(t TB, h *Handler, matchers ...Matcher) Record {
+	t.Helper()
+
+	r, ok := Find(h, matchers...)
+	if !ok {
+		t.Errorf("no record was logged matching %s; logged:\n%s", describe(matchers), dump(h.Records()))
+	}
+
+	return r
+}
+
+// AssertNotLogged fails the test if a record matching every matcher was
+// captured by h.
+func AssertNotLogged(t TB, h *Handler, matchers ...Matcher) {
+	t.Helper()
+
+	if r, ok := Find(h, matchers...); ok {
+		t.Errorf("a record was logged matching %s: %s", describe(matchers), dump([]Record{r}))
+	}
+}
+
+func matchAll(r Record, matchers []Matcher) bool {
+	for _, m := range matchers {
+		if !m.match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func describe(matchers []Matcher) string {
+	descriptions := make([]string, len(matchers))
+	for i, m := range matchers {
+		descriptions[i] = m.String()
+	}
+	return "[" + strings.Join(descriptions, " ") + "]"
+}
+
+// dump formats records for use in failure messages, one per line.
+func dump(records []Record) string {
+	var b strings.Builder
+	for _, r := range records {
+		attrs, _ := json.Marshal(r.Attrs)
+		fmt.Fprintf(&b, "\t%s %q %s\n", r.Level, r.Message, attrs)
+	}
+	return b.String()
+}
+
+// jsonEqual reports whether a and b have equal JSON representations.
+func jsonEqual(a, b any) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(v any) any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	var x any
+	if err := json.Unmarshal(b, &x); err != nil {
+		return v
+	}
+
+	return x
+}
@@ -0,0 +1,238 @@
+// Package logtest provides utilities for testing code that logs using the
+// logging package, such as handlers and services using the middleware and
+// interceptors.
+package logtest
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Record is a log record captured by a Handler.
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+
+	// Attrs holds the attributes of the record, including those added to the
+	// handler, with any groups open at the time nesting them. The value of a
+	// group is a map of its attributes, and values implementing
+	// json.Marshaler, such as payloads, are decoded from their JSON.
+	Attrs map[string]any
+}
+
+// Attr returns the value of the attribute at the given path, which is the key
+// of the attribute followed by those of any nested groups and JSON objects,
+// such as Attr("jsonPayload", "message").
+func (r Record) Attr(path ...string) (any, bool) {
+	var v any = r.Attrs
+	for _, key := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// Handler is a handler that captures every record in memory, such that tests
+// may assert what was logged. It is safe for concurrent use.
+type Handler struct {
+	level   slog.Leveler
+	records *records
+
+	// State accumulated by WithAttrs and WithGroup. The attributes are held
+	// already resolved.
+	groups []string
+	attrs  map[string]any
+}
+
+// records holds the records captured by a Handler and the handlers derived
+// from it.
+type records struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+// NewHandler returns a new Handler capturing records at the given level and
+// above. If level is nil every record is captured.
+func NewHandler(level slog.Leveler) *Handler {
+	return &Handler{
+		level:   level,
+		records: &records{},
+		attrs:   make(map[string]any),
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.level == nil || level >= h.level.Level()
+}
+
+// Handle captures r.
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	attrs := copyMap(h.attrs)
+
+	// Groups without attributes are omitted, as by the built-in handlers.
+	if r.NumAttrs() > 0 {
+		group := groupMap(attrs, h.groups)
+		r.Attrs(func(a slog.Attr) {
+			addAttr(group, a)
+		})
+	}
+
+	h.records.mu.Lock()
+	defer h.records.mu.Unlock()
+
+	h.records.records = append(h.records.records, Record{
+		Time:    r.Time,
+		Level:   r.Level,
+		Message: r.Message,
+		Attrs:   attrs,
+	})
+
+	return nil
+}
+
+// WithAttrs returns a new Handler whose attributes consists of h's attributes
+// followed by attrs. The new handler shares the records of h.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := *h
+	h2.attrs = copyMap(h.attrs)
+
+	group := groupMap(h2.attrs, h.groups)
+	for _, a := range attrs {
+		addAttr(group, a)
+	}
+
+	return &h2
+}
+
+// WithGroup returns a new Handler whose attributes consists of h's attributes
+// followed by a group with the given name. The new handler shares the records
+// of h.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &h2
+}
+
+// Records returns the records captured so far, in the order they were
+// logged.
+func (h *Handler) Records() []Record {
+	h.records.mu.Lock()
+	defer h.records.mu.Unlock()
+
+	return append([]Record(nil), h.records.records...)
+}
+
+// Reset discards the records captured so far.
+func (h *Handler) Reset() {
+	h.records.mu.Lock()
+	defer h.records.mu.Unlock()
+
+	h.records.records = nil
+}
+
+// addAttr adds the resolved value of a to m. The attributes of a group with
+// an empty key are added to m itself, while empty groups are ignored.
+func addAttr(m map[string]any, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() != slog.KindGroup {
+		if a.Key != "" {
+			m[a.Key] = resolveValue(a.Value)
+		}
+		return
+	}
+
+	attrs := a.Value.Group()
+	if len(attrs) == 0 {
+		return
+	}
+
+	if a.Key == "" {
+		for _, ga := range attrs {
+			addAttr(m, ga)
+		}
+		return
+	}
+
+	group, ok := m[a.Key].(map[string]any)
+	if !ok {
+		group = make(map[string]any, len(attrs))
+		m[a.Key] = group
+	}
+
+	for _, ga := range attrs {
+		addAttr(group, ga)
+	}
+}
+
+// resolveValue returns the value held by v. Values implementing
+// json.Marshaler are decoded from their JSON, so that payloads may be
+// inspected.
+func resolveValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindAny:
+		if m, ok := v.Any().(json.Marshaler); ok {
+			b, err := m.MarshalJSON()
+			if err != nil {
+				return v.Any()
+			}
+
+			var x any
+			if err := json.Unmarshal(b, &x); err != nil {
+				return v.Any()
+			}
+			return x
+		}
+		return v.Any()
+	case slog.KindLogValuer:
+		return resolveValue(v.Resolve())
+	default:
+		return v.Any()
+	}
+}
+
+// groupMap returns the map of the innermost of the groups within m, creating
+// the maps of any groups not yet present.
+func groupMap(m map[string]any, groups []string) map[string]any {
+	for _, name := range groups {
+		group, ok := m[name].(map[string]any)
+		if !ok {
+			group = make(map[string]any)
+			m[name] = group
+		}
+		m = group
+	}
+	return m
+}
+
+// copyMap returns a deep copy of m, copying the maps of nested groups so that
+// they may be modified without affecting m.
+func copyMap(m map[string]any) map[string]any {
+	m2 := make(map[string]any, len(m))
+	for k, v := range m {
+		if group, ok := v.(map[string]any); ok {
+			v = copyMap(group)
+		}
+		m2[k] = v
+	}
+	return m2
+}
@@ -0,0 +1,22 @@
+package logtest
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+// ServeHTTP serves r using next wrapped by logging.RequestLogger, capturing
+// its records in the returned Handler, along with those of loggers returned
+// by logging.FromContext within next. The response is written to the
+// returned recorder.
+func ServeHTTP(next http.HandlerFunc, r *http.Request, opts ...logging.Option) (*httptest.ResponseRecorder, *Handler) {
+	h := NewHandler(nil)
+	opts = append([]logging.Option{logging.WithHandler(h)}, opts...)
+
+	w := httptest.NewRecorder()
+	logging.RequestLogger(opts...)(next)(w, r)
+
+	return w, h
+}
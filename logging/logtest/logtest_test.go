@@ -0,0 +1,207 @@
+package logtest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kapetndev/connect/logging"
+	"github.com/kapetndev/connect/logging/logtest"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+)
+
+// loggingServer is an echo server which logs using the logger of each unary
+// request.
+type loggingServer struct {
+	logtest.EchoServer
+}
+
+// Echo logs using the logger of the request and responds with the message of
+// the request.
+func (s *loggingServer) Echo(ctx context.Context, in *echopb.EchoRequest) (*echopb.EchoResponse, error) {
+	logging.FromContext(ctx).Info(ctx, "engage", "captain", "picard")
+	return s.EchoServer.Echo(ctx, in)
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("captures records with their attributes and groups resolved", func(t *testing.T) {
+		h := logtest.NewHandler(nil)
+
+		logger := logging.New(h).With("captain", "picard").WithGroup("ship")
+		logger.Warning(context.Background(), "shields down", "name", "enterprise", slog.Group("warp", slog.Int("factor", 9)))
+
+		records := h.Records()
+		if len(records) != 1 {
+			t.Fatalf("records are not equal: %d != %d", len(records), 1)
+		}
+
+		r := records[0]
+		if r.Level != logging.LevelWarning {
+			t.Errorf("levels are not equal: %s != %s", r.Level, logging.LevelWarning)
+		}
+		if r.Message != "shields down" {
+			t.Errorf("messages are not equal: %s != %s", r.Message, "shields down")
+		}
+
+		for path, want := range map[string]any{
+			"captain":           "picard",
+			"ship.name":         "enterprise",
+			"ship.warp.factor":  int64(9),
+			"ship.warp.missing": nil,
+		} {
+			got, _ := r.Attr(strings.Split(path, ".")...)
+			if got != want {
+				t.Errorf("attributes are not equal: %s: %v != %v", path, got, want)
+			}
+		}
+	})
+
+	t.Run("ignores records below the level", func(t *testing.T) {
+		h := logtest.NewHandler(logging.LevelInfo)
+
+		logger := logging.New(h)
+		logger.Debug(context.Background(), "scanning")
+		logger.Info(context.Background(), "engage")
+
+		logtest.AssertNotLogged(t, h, logtest.Message("scanning"))
+		logtest.AssertLogged(t, h, logtest.Level(logging.LevelInfo), logtest.Message("engage"))
+	})
+
+	t.Run("discards the records once reset", func(t *testing.T) {
+		h := logtest.NewHandler(nil)
+		logging.New(h).Info(context.Background(), "engage")
+
+		h.Reset()
+		if records := h.Records(); len(records) != 0 {
+			t.Errorf("records are not equal: %d != %d", len(records), 0)
+		}
+	})
+
+	t.Run("matches attributes whose keys contain dots", func(t *testing.T) {
+		h := logtest.NewHandler(nil)
+		logging.New(h).Info(context.Background(), "engage", "http.request.method", http.MethodGet)
+
+		logtest.AssertLogged(t, h, logtest.Attr("http.request.method", http.MethodGet))
+	})
+}
+
+func TestServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	t.Run("captures the records of the RequestLogger and handlers", func(t *testing.T) {
+		w, h := logtest.ServeHTTP(func(w http.ResponseWriter, r *http.Request) {
+			logging.FromContext(r.Context()).Info(r.Context(), "engage", "captain", "picard")
+			w.WriteHeader(http.StatusTeapot)
+			w.Write([]byte(`{"captain":{"name":"picard"}}`))
+		}, httptest.NewRequest(http.MethodGet, "/bridge", nil))
+
+		if w.Code != http.StatusTeapot {
+			t.Errorf("status codes are not equal: %d != %d", w.Code, http.StatusTeapot)
+		}
+
+		logtest.AssertLogged(t, h, logtest.Message("engage"), logtest.Attr("captain", "picard"))
+		logtest.AssertLogged(t, h,
+			logtest.Method(http.MethodGet),
+			logtest.Path("/bridge"),
+			logtest.Status(http.StatusTeapot),
+			logtest.AttrPath([]string{logging.ResponseKey, "captain", "name"}, "picard"),
+		)
+	})
+
+	t.Run("applies the options to the RequestLogger", func(t *testing.T) {
+		_, h := logtest.ServeHTTP(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, httptest.NewRequest(http.MethodGet, "/bridge", nil), logging.WithStatusLevels(func(int) slog.Level { return logging.LevelError }))
+
+		logtest.AssertLogged(t, h, logtest.Level(logging.LevelError), logtest.Status(http.StatusNotFound))
+	})
+}
+
+func TestHandler_Interceptors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("captures the records of the interceptors and handlers", func(t *testing.T) {
+		closer, client, h := logtest.NewServer(t, &loggingServer{}, logging.WithPayloadCapture(true))
+		defer closer()
+
+		if _, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: "make it so"}); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		logtest.AssertLogged(t, h, logtest.Message("engage"), logtest.Attr("captain", "picard"))
+		logtest.AssertLogged(t, h,
+			logtest.Level(logging.LevelInfo),
+			logtest.Path("/echo.v1.EchoService/Echo"),
+			logtest.Code(codes.OK),
+			logtest.RequestField("message", "make it so"),
+			logtest.ResponseField("message", "make it so"),
+		)
+	})
+
+	t.Run("fails calls whose message names a status code", func(t *testing.T) {
+		closer, client, h := logtest.NewServer(t, nil, logging.WithCodeLevels(func(codes.Code) slog.Level { return logging.LevelError }))
+		defer closer()
+
+		_, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: "NotFound"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("error codes are not equal: %s != %s", status.Code(err), codes.NotFound)
+		}
+
+		logtest.AssertLogged(t, h, logtest.Level(logging.LevelError), logtest.Code(codes.NotFound))
+		logtest.AssertNotLogged(t, h, logtest.Code(codes.OK))
+	})
+
+	t.Run("captures the records of streams", func(t *testing.T) {
+		closer, client, h := logtest.NewServer(t, nil)
+		defer closer()
+
+		stream, err := client.ClientStreamingEcho(context.Background())
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		for _, msg := range []string{"make", "it", "so"} {
+			if err := stream.Send(&echopb.ClientStreamingEchoRequest{Message: msg}); err != nil {
+				t.Fatalf("error was not <nil>: %s", err)
+			}
+		}
+
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		if resp.Message != "make it so" {
+			t.Errorf("messages are not equal: %s != %s", resp.Message, "make it so")
+		}
+
+		logtest.AssertLogged(t, h, logtest.Path("/echo.v1.EchoService/ClientStreamingEcho"), logtest.Code(codes.OK))
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("captures the records of the client interceptors", func(t *testing.T) {
+		closer, client, h := logtest.NewClient(t, nil)
+		defer closer()
+
+		_, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: "NotFound"})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("error codes are not equal: %s != %s", status.Code(err), codes.NotFound)
+		}
+
+		logtest.AssertLogged(t, h,
+			logtest.Path("/echo.v1.EchoService/Echo"),
+			logtest.Attr(logging.KindKey, "client"),
+			logtest.Code(codes.NotFound),
+		)
+	})
+}
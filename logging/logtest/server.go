@@ -0,0 +1,143 @@
+package logtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kapetndev/connect/logging"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+	"github.com/kapetndev/grpctest"
+)
+
+// EchoServer is an implementation of the echo test service which responds
+// with the message of each request. Should the message be the name of a gRPC
+// status code other than OK, such as "NotFound", the call instead fails with
+// that code. It may be embedded by servers overriding some of its methods.
+type EchoServer struct {
+	echopb.UnimplementedEchoServiceServer
+}
+
+// Echo responds with the message of the request.
+func (s *EchoServer) Echo(ctx context.Context, in *echopb.EchoRequest) (*echopb.EchoResponse, error) {
+	if err := codeError(in.Message); err != nil {
+		return nil, err
+	}
+	return &echopb.EchoResponse{Message: in.Message}, nil
+}
+
+// ServerStreamingEcho responds with the message of the request three times.
+func (s *EchoServer) ServerStreamingEcho(in *echopb.ServerStreamingEchoRequest, ss echopb.EchoService_ServerStreamingEchoServer) error {
+	if err := codeError(in.Message); err != nil {
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := ss.Send(&echopb.ServerStreamingEchoResponse{Message: in.Message}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClientStreamingEcho responds with the messages of every request, separated
+// by spaces.
+func (s *EchoServer) ClientStreamingEcho(ss echopb.EchoService_ClientStreamingEchoServer) error {
+	var msgs []string
+	for {
+		in, err := ss.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := codeError(in.Message); err != nil {
+			return err
+		}
+		msgs = append(msgs, in.Message)
+	}
+	return ss.SendAndClose(&echopb.ClientStreamingEchoResponse{Message: strings.Join(msgs, " ")})
+}
+
+// codeError returns an error with the code named by msg, if any.
+func codeError(msg string) error {
+	for c := codes.Canceled; c <= codes.Unauthenticated; c++ {
+		if c.String() == msg {
+			return status.Error(c, msg)
+		}
+	}
+	return nil
+}
+
+// Serve starts srv, an implementation of the echo test service, on a server
+// created with serverOpts, and returns a client connected to it using
+// dialOpts. If srv is nil an EchoServer is used. The returned Closer stops
+// the server once the calls in progress have finished.
+func Serve(t TB, srv echopb.EchoServiceServer, serverOpts []grpc.ServerOption, dialOpts ...grpc.DialOption) (grpctest.Closer, echopb.EchoServiceClient) {
+	t.Helper()
+
+	if srv == nil {
+		srv = &EchoServer{}
+	}
+
+	s := grpctest.NewServer(serverOpts...)
+
+	conn, err := s.ClientConn(dialOpts...)
+	if err != nil {
+		t.Fatalf("failed to connect to the server: %s", err)
+	}
+
+	echopb.RegisterEchoServiceServer(s, srv)
+	s.Serve()
+
+	return s.Close, echopb.NewEchoServiceClient(conn)
+}
+
+// NewServer serves srv with the logging server interceptors installed,
+// capturing their records in the returned Handler, along with those of
+// loggers returned by logging.FromContext within srv. If srv is nil an
+// EchoServer is used.
+func NewServer(t TB, srv echopb.EchoServiceServer, opts ...logging.Option) (grpctest.Closer, echopb.EchoServiceClient, *Handler) {
+	t.Helper()
+
+	h := NewHandler(nil)
+	opts = append([]logging.Option{logging.WithHandler(h)}, opts...)
+
+	closer, client := Serve(t, srv, []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			logging.UnaryServerInterceptor(opts...),
+		),
+		grpc.ChainStreamInterceptor(
+			logging.StreamServerInterceptor(opts...),
+		),
+	})
+
+	return closer, client, h
+}
+
+// NewClient serves srv and returns a client with the logging client
+// interceptors installed, capturing their records in the returned Handler.
+// If srv is nil an EchoServer is used.
+func NewClient(t TB, srv echopb.EchoServiceServer, opts ...logging.Option) (grpctest.Closer, echopb.EchoServiceClient, *Handler) {
+	t.Helper()
+
+	h := NewHandler(nil)
+	opts = append([]logging.Option{logging.WithHandler(h)}, opts...)
+
+	closer, client := Serve(t, srv, nil,
+		grpc.WithChainUnaryInterceptor(
+			logging.UnaryClientInterceptor(opts...),
+		),
+		grpc.WithChainStreamInterceptor(
+			logging.StreamClientInterceptor(opts...),
+		),
+	)
+
+	return closer, client, h
+}
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"time"
 
@@ -20,46 +21,110 @@ func RequestLogger(opts ...Option) transport.Middleware {
 			startTime := time.Now()
 			ctx := r.Context()
 
+			// Apply any rules matching the path.
+			o := o.forRoute(r.URL.Path)
+			sampled := o.sampled()
+
+			// Propagate the trace context of the request, if any, so that log
+			// entries may be correlated with the trace.
+			if tc, ok := TraceFromHTTPHeader(r.Header); ok {
+				ctx = NewTraceContext(ctx, tc)
+			}
+
 			// Configure the logger passed into the middleware.
-			logger := New(o.handler)
+			h, buffer := o.requestHandler()
+			scope := newRequestScope(New(h))
+
+			// Write any buffered records should the handler panic.
+			if buffer != nil {
+				defer flushOnPanic(ctx, buffer)
+			}
 
 			// Wrap the response writer so we may capture the status code and payload
 			// from the handler.
 			rw := transport.NewResponseWriter(w)
 
+			// Capture the request body before the handler consumes it. The body is
+			// replaced so that the handler may still read it in full.
+			var requestAttrs []slog.Attr
+			if sampled && o.logRequests {
+				if body, truncated, err := captureRequestBody(r, o.maxRequestBytes); err == nil && len(body) > 0 {
+					requestAttrs = append(requestAttrs, bytesRequestAttr(o.redactor.redactJSON(body), truncated, o.maxRequestBytes))
+				}
+			}
+
 			// Invoke the hander and log the response.
-			next.ServeHTTP(rw, r.WithContext(NewContext(ctx, logger)))
+			next.ServeHTTP(rw, r.WithContext(newRequestContext(ctx, scope)))
+
+			// Buffered records are only written if the request failed.
+			if rw.StatusCode() >= http.StatusInternalServerError {
+				buffer.flush(ctx)
+			} else {
+				buffer.discard()
+			}
 
 			// Suppress request logs matching some pattern.
-			if o.shouldDiscard(ctx, r.URL.Path, nil) {
+			if !sampled || o.shouldDiscard(ctx, r.URL.Path, nil) {
 				return
 			}
 
 			// Log the request/response.
-			o.handler.Handle(ctx, newRequestRecord(ctx, startTime, rw, r))
+			record := newRequestRecord(ctx, o.statusLevel(rw.StatusCode()), startTime, rw, r)
+			record.AddAttrs(scope.attrs()...)
+			record.AddAttrs(o.responseAttrs(rw.Payload())...)
+			record.AddAttrs(requestAttrs...)
+			o.handle(ctx, record)
 		}
 	}
 }
 
-func newRequestRecord(ctx context.Context, t time.Time, rw *transport.ResponseWriter, r *http.Request) slog.Record {
-	statusCode := rw.StatusCode()
+func newRequestRecord(ctx context.Context, level slog.Level, t time.Time, rw *transport.ResponseWriter, r *http.Request) slog.Record {
+	record := newCommonRecord(ctx, level, t, r.Method, r.URL.Path)
+
+	record.AddAttrs(
+		slog.Int(StatusKey, rw.StatusCode()),
+		slog.String(URLKey, requestURL(r)),
+		slog.String(ProtocolKey, r.Proto),
+		slog.Int(ResponseSizeKey, rw.Size()),
+	)
 
-	level := slog.LevelInfo
-	if statusCode >= http.StatusBadRequest {
-		level = slog.LevelError
+	if r.ContentLength >= 0 {
+		record.AddAttrs(slog.Int64(RequestSizeKey, r.ContentLength))
+	}
+	if r.RemoteAddr != "" {
+		record.AddAttrs(slog.String(RemoteIPKey, r.RemoteAddr))
+	}
+	if addr, ok := ctx.Value(http.LocalAddrContextKey).(net.Addr); ok {
+		record.AddAttrs(slog.String(ServerIPKey, addr.String()))
+	}
+	if ua := r.UserAgent(); ua != "" {
+		record.AddAttrs(slog.String(UserAgentKey, ua))
+	}
+	if referer := r.Referer(); referer != "" {
+		record.AddAttrs(slog.String(RefererKey, referer))
 	}
 
-	record := newCommonRecord(ctx, level, t, r.Method, r.URL.Path)
+	return record
+}
 
-	record.AddAttrs(slog.Int(StatusKey, statusCode))
+// requestURL returns the absolute URL of the request r. The URL of a server
+// request typically only contains the path and query, so the host and scheme
+// are filled in from the request itself.
+func requestURL(r *http.Request) string {
+	u := *r.URL
 
-	// If the response includes a payload then add it to the log entry. This
-	// assumes that the payload is a JSON object.
-	if rw.Payload() != nil {
-		record.AddAttrs(slog.Any(ResponseKey, byteSliceMarshallable(rw.Payload())))
+	if u.Host == "" {
+		u.Host = r.Host
 	}
 
-	return record
+	if u.Scheme == "" {
+		u.Scheme = "http"
+		if r.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+
+	return u.String()
 }
 
 // byteSliceMarshallable is a wrapper type allowing us to embed a JSON object
@@ -0,0 +1,149 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"golang.org/x/exp/slog"
+)
+
+// Sink is a handler to which a MultiHandler dispatches records, along with
+// the minimum level of the records it is passed. If Level is nil only the
+// handler itself decides which records it handles.
+type Sink struct {
+	Handler slog.Handler
+	Level   slog.Leveler
+}
+
+// enabled reports whether the sink handles records at the given level.
+func (s Sink) enabled(ctx context.Context, level slog.Level) bool {
+	if s.Level != nil && level < s.Level.Level() {
+		return false
+	}
+	return s.Handler.Enabled(ctx, level)
+}
+
+// MultiHandler is a handler that dispatches each record to several sinks, for
+// example writing every record to stdout while also writing errors to a
+// dedicated sink. Each sink has its own level, which may be changed at runtime
+// by passing a *slog.LevelVar.
+type MultiHandler struct {
+	sinks []Sink
+}
+
+// NewMultiHandler returns a new MultiHandler dispatching records to each of
+// the sinks.
+func NewMultiHandler(sinks ...Sink) *MultiHandler {
+	return &MultiHandler{
+		sinks: append([]Sink(nil), sinks...),
+	}
+}
+
+// Enabled reports whether any sink handles records at the given level.
+func (h *MultiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, s := range h.sinks {
+		if s.enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle passes r to every sink handling records at its level. Every sink is
+// passed the record even if an earlier sink fails, and the errors of all
+// those that fail are returned together.
+func (h *MultiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, s := range h.sinks {
+		if !s.enabled(ctx, r.Level) {
+			continue
+		}
+
+		// Each sink is passed its own copy of the record so that no sink may
+		// modify the record seen by another.
+		if err := s.Handler.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs...)
+}
+
+// WithAttrs returns a new MultiHandler whose sinks' attributes consists of
+// their attributes followed by attrs.
+func (h *MultiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	sinks := make([]Sink, len(h.sinks))
+	for i, s := range h.sinks {
+		sinks[i] = Sink{Handler: s.Handler.WithAttrs(attrs), Level: s.Level}
+	}
+	return &MultiHandler{sinks: sinks}
+}
+
+// WithGroup returns a new MultiHandler whose sinks' attributes consists of
+// their attributes followed by a group with the given name.
+func (h *MultiHandler) WithGroup(name string) slog.Handler {
+	sinks := make([]Sink, len(h.sinks))
+	for i, s := range h.sinks {
+		sinks[i] = Sink{Handler: s.Handler.WithGroup(name), Level: s.Level}
+	}
+	return &MultiHandler{sinks: sinks}
+}
+
+// multiError is an error wrapping several errors, equivalent to those
+// returned by errors.Join. It also implements Is and As so that the wrapped
+// errors may be found on versions of Go predating errors.Join.
+type multiError struct {
+	errs []error
+}
+
+// joinErrors returns an error wrapping the non-nil errors, or nil if there
+// are none.
+func joinErrors(errs ...error) error {
+	e := &multiError{}
+	for _, err := range errs {
+		if err != nil {
+			e.errs = append(e.errs, err)
+		}
+	}
+
+	if len(e.errs) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+// Error returns the messages of the wrapped errors, separated by newlines.
+func (e *multiError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the wrapped errors.
+func (e *multiError) Unwrap() []error {
+	return e.errs
+}
+
+// Is reports whether any of the wrapped errors matches target.
+func (e *multiError) Is(target error) bool {
+	for _, err := range e.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the wrapped errors that matches target, and if so
+// sets target to that error value and returns true.
+func (e *multiError) As(target any) bool {
+	for _, err := range e.errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+// errWriter is a writer that always fails.
+type errWriter struct {
+	err error
+}
+
+func (w errWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestMultiHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dispatches records to each sink handling their level", func(t *testing.T) {
+		stdout, errout := &bytes.Buffer{}, &bytes.Buffer{}
+
+		h := logging.NewMultiHandler(
+			logging.Sink{Handler: slog.NewJSONHandler(stdout)},
+			logging.Sink{Handler: slog.NewJSONHandler(errout), Level: logging.LevelError},
+		)
+
+		logger := logging.New(h)
+		logger.Info(context.Background(), "engage")
+		logger.Error(context.Background(), "warp core breach")
+
+		assertJSON(t, "messages", messages(t, stdout.Bytes()), `["engage","warp core breach"]`)
+		assertJSON(t, "messages", messages(t, errout.Bytes()), `["warp core breach"]`)
+	})
+
+	t.Run("is enabled if any sink handles the level", func(t *testing.T) {
+		h := logging.NewMultiHandler(
+			logging.Sink{Handler: slog.NewJSONHandler(&bytes.Buffer{}), Level: logging.LevelError},
+			logging.Sink{Handler: slog.HandlerOptions{Level: logging.LevelWarning}.NewJSONHandler(&bytes.Buffer{})},
+		)
+
+		if h.Enabled(context.Background(), logging.LevelInfo) {
+			t.Error("handler was enabled at the info level")
+		}
+		if !h.Enabled(context.Background(), logging.LevelWarning) {
+			t.Error("handler was not enabled at the warning level")
+		}
+	})
+
+	t.Run("applies attributes and groups to every sink", func(t *testing.T) {
+		a, b := &bytes.Buffer{}, &bytes.Buffer{}
+
+		h := logging.NewMultiHandler(
+			logging.Sink{Handler: slog.NewJSONHandler(a)},
+			logging.Sink{Handler: slog.NewJSONHandler(b)},
+		)
+
+		logger := logging.New(h).With("captain", "picard").WithGroup("ship")
+		logger.Info(context.Background(), "engage", "name", "enterprise")
+
+		for _, buf := range []*bytes.Buffer{a, b} {
+			entries := decodeLogEntries(t, buf.Bytes())
+			assertJSON(t, "captain", entries[0]["captain"], `"picard"`)
+			assertJSON(t, "ship", entries[0]["ship"], `{"name":"enterprise"}`)
+		}
+	})
+
+	t.Run("writes to every sink and returns the errors of those that fail", func(t *testing.T) {
+		errShields := errors.New("shields down")
+		errWarp := errors.New("warp core breach")
+		buf := &bytes.Buffer{}
+
+		h := logging.NewMultiHandler(
+			logging.Sink{Handler: slog.NewJSONHandler(errWriter{err: errShields})},
+			logging.Sink{Handler: slog.NewJSONHandler(buf)},
+			logging.Sink{Handler: slog.NewJSONHandler(errWriter{err: errWarp})},
+		)
+
+		err := h.Handle(context.Background(), slog.NewRecord(time.Now(), logging.LevelInfo, "engage", 0))
+		if !errors.Is(err, errShields) || !errors.Is(err, errWarp) {
+			t.Errorf("error does not wrap each sink error: %v", err)
+		}
+		if err.Error() != "shields down\nwarp core breach" {
+			t.Errorf("error messages are not equal: %q != %q", err.Error(), "shields down\nwarp core breach")
+		}
+
+		assertJSON(t, "messages", messages(t, buf.Bytes()), `["engage"]`)
+	})
+
+	t.Run("returns no error when every sink succeeds", func(t *testing.T) {
+		h := logging.NewMultiHandler(logging.Sink{Handler: slog.NewJSONHandler(&bytes.Buffer{})})
+
+		if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), logging.LevelInfo, "engage", 0)); err != nil {
+			t.Errorf("error was not <nil>: %s", err)
+		}
+	})
+}
@@ -7,16 +7,51 @@ import (
 	"golang.org/x/exp/slog"
 )
 
+// Default limits on the payload captured from a single request or stream.
+const (
+	defaultMaxRequestBytes   = 64 << 10
+	defaultMaxStreamMessages = 100
+	defaultMaxStreamBytes    = 64 << 10
+)
+
 var defaultOptions = options{
-	handler:       slog.NewTextHandler(os.Stdout),
-	shouldDiscard: permitAllRequestLogs,
+	handler:           slog.NewTextHandler(os.Stdout),
+	shouldDiscard:     permitAllRequestLogs,
+	codeLevel:         DefaultCodeLevel,
+	statusLevel:       DefaultStatusLevel,
+	maxRequestBytes:   defaultMaxRequestBytes,
+	maxStreamMessages: defaultMaxStreamMessages,
+	maxStreamBytes:    defaultMaxStreamBytes,
+	redactPlaceholder: DefaultRedactionPlaceholder,
+	logResponses:      true,
+	sampleRate:        1,
 }
 
 // options describe the full set of options that may be configured to influence
 // the output of the logger.
 type options struct {
-	handler       slog.Handler
-	shouldDiscard FilterFunc
+	handler           slog.Handler
+	shouldDiscard     FilterFunc
+	codeLevel         CodeLevelFunc
+	statusLevel       StatusLevelFunc
+	logRequests       bool
+	maxRequestBytes   int
+	maxStreamMessages int
+	maxStreamBytes    int
+	redactPaths       []string
+	redactPlaceholder string
+	logResponses      bool
+	minLevel          slog.Leveler
+	sampleRate        float64
+	rules             []rule
+
+	bufferLogs         bool
+	bufferThreshold    slog.Level
+	maxBufferedRecords int
+
+	// redactor is built from the redaction options once all options have
+	// been applied.
+	redactor *redactor
 }
 
 // Option is a function that can configure one or more logging options.
@@ -41,6 +76,131 @@ func WithFilter(f FilterFunc) Option {
 	}
 }
 
+// WithCodeLevels returns a logging option to customise the level of RPC log
+// entries based on the gRPC status code of the call.
+func WithCodeLevels(f CodeLevelFunc) Option {
+	return func(o *options) {
+		o.codeLevel = f
+	}
+}
+
+// WithStatusLevels returns a logging option to customise the level of HTTP
+// request log entries based on the status code of the response.
+func WithStatusLevels(f StatusLevelFunc) Option {
+	return func(o *options) {
+		o.statusLevel = f
+	}
+}
+
+// WithStreamPayloadLimit returns a logging option to cap the number of
+// messages, and the total number of bytes of their JSON encoding, captured
+// from a single stream. Messages beyond either limit are not logged.
+func WithStreamPayloadLimit(messages, bytes int) Option {
+	return func(o *options) {
+		o.maxStreamMessages = messages
+		o.maxStreamBytes = bytes
+	}
+}
+
+// WithRequestPayload returns a logging option to include the request payload
+// in log entries alongside the response. This covers unary request messages,
+// messages received on a stream and HTTP request bodies. Payloads larger than
+// maxBytes are not logged, only marked as truncated.
+func WithRequestPayload(maxBytes int) Option {
+	return func(o *options) {
+		o.logRequests = true
+		o.maxRequestBytes = maxBytes
+	}
+}
+
+// WithRedactedFields returns a logging option to replace the value of the
+// given fields within logged payloads with a placeholder. Each field is given
+// either as a dot separated path of JSON field names, such as "user.password",
+// or as a JSON pointer, such as "/user/password". A path segment of "*"
+// matches any field or array element. Fields of protobuf messages marked with
+// the debug_redact option are always redacted.
+func WithRedactedFields(paths ...string) Option {
+	return func(o *options) {
+		o.redactPaths = append(o.redactPaths, paths...)
+	}
+}
+
+// WithRedactionPlaceholder returns a logging option to customise the value
+// substituted for redacted fields.
+func WithRedactionPlaceholder(placeholder string) Option {
+	return func(o *options) {
+		o.redactPlaceholder = placeholder
+	}
+}
+
+// WithPayloadCapture returns a logging option to turn the capture of payloads
+// on or off. When off neither response nor request payloads are logged, and
+// stream messages are not collected. When on request payloads are logged
+// along with responses, subject to the limit given to WithRequestPayload.
+// This is most useful within a rule, for example to silence a chatty stream.
+func WithPayloadCapture(enabled bool) Option {
+	return func(o *options) {
+		o.logResponses = enabled
+		o.logRequests = enabled
+	}
+}
+
+// WithMinLevel returns a logging option to discard request log entries below
+// the given level, as determined by WithCodeLevels or WithStatusLevels. This
+// does not affect the level of the logger passed to handlers.
+func WithMinLevel(level slog.Leveler) Option {
+	return func(o *options) {
+		o.minLevel = level
+	}
+}
+
+// WithSampleRate returns a logging option to log only a fraction of requests,
+// chosen at random. A rate of 0.1 logs roughly one request in ten. Rates of 1
+// or greater log every request.
+func WithSampleRate(rate float64) Option {
+	return func(o *options) {
+		o.sampleRate = rate
+	}
+}
+
+// WithRule returns a logging option to apply the given options only to
+// requests whose gRPC full method or HTTP path matches pattern. Patterns use
+// the syntax of path.Match, for example "/echo.v1.EchoService/*" or
+// "/healthz", where "*" does not match a "/". A malformed pattern matches
+// nothing. Where several rules match a request their options are applied in
+// the order the rules were given, after all other options.
+//
+// For example, to silence successful health checks and drop the payloads of
+// a streaming method
+//
+//	logging.UnaryServerInterceptor(
+//		logging.WithRule("/grpc.health.v1.Health/*", logging.WithMinLevel(logging.LevelWarning)),
+//		logging.WithRule("/echo.v1.EchoService/ServerStreamingEcho", logging.WithPayloadCapture(false)),
+//	)
+func WithRule(pattern string, opts ...Option) Option {
+	return func(o *options) {
+		o.rules = append(o.rules, rule{pattern: pattern, opts: opts})
+	}
+}
+
+// WithLogBuffering returns a logging option to hold the records logged below
+// the threshold level by the request scoped logger, see FromContext, in memory
+// for the life of the request. The records are written only if the request
+// fails, that is if a gRPC call returns an error, a HTTP request responds
+// with a 5xx status code, or the handler panics. Otherwise they are
+// discarded. At most maxRecords are held, beyond which the oldest are
+// dropped.
+//
+// Buffered records are written regardless of the level of the handler, so
+// that debug records may be kept for failed requests alone.
+func WithLogBuffering(threshold slog.Level, maxRecords int) Option {
+	return func(o *options) {
+		o.bufferLogs = true
+		o.bufferThreshold = threshold
+		o.maxBufferedRecords = maxRecords
+	}
+}
+
 func permitAllRequestLogs(context.Context, string, error) bool {
 	return false
 }
@@ -50,5 +210,6 @@ func applyOptions(opts []Option) options {
 	for _, opt := range opts {
 		opt(&cfg)
 	}
+	cfg.redactor = newRedactor(cfg.redactPlaceholder, cfg.redactPaths)
 	return cfg
 }
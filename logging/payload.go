@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// responseAttrs returns the attributes logging the response payload m, along
+// with its size in the case of a single message. The payload itself is only
+// logged if payload capture is enabled. This assumes that the payload is a
+// JSON object.
+func (o options) responseAttrs(m interface{}) []slog.Attr {
+	switch p := m.(type) {
+	case proto.Message:
+		attrs := []slog.Attr{slog.Int(ResponseSizeKey, proto.Size(p))}
+		if !o.logResponses {
+			return attrs
+		}
+
+		return append(attrs, slog.Any(ResponseKey, &jsonpbMarshalleble{Message: p, redactor: o.redactor}))
+	case *streamPayload:
+		if o.logResponses && !p.empty() {
+			return []slog.Attr{slog.Any(ResponseKey, p)}
+		}
+	case []byte:
+		if o.logResponses && p != nil {
+			return []slog.Attr{slog.Any(ResponseKey, byteSliceMarshallable(o.redactor.redactJSON(p)))}
+		}
+	}
+
+	return nil
+}
+
+// requestAttrs returns the attributes logging the request payload m, along
+// with its size in the case of a single message. The payload itself is only
+// logged if request payload logging is enabled.
+func (o options) requestAttrs(m interface{}) []slog.Attr {
+	switch p := m.(type) {
+	case proto.Message:
+		attrs := []slog.Attr{slog.Int(RequestSizeKey, proto.Size(p))}
+		if !o.logRequests {
+			return attrs
+		}
+
+		b, err := (&jsonpbMarshalleble{Message: p, redactor: o.redactor}).MarshalJSON()
+		if err != nil {
+			return attrs
+		}
+
+		return append(attrs, bytesRequestAttr(b, len(b) > o.maxRequestBytes, o.maxRequestBytes))
+	case *streamPayload:
+		if !o.logRequests || p.empty() {
+			return nil
+		}
+
+		return []slog.Attr{slog.Any(RequestKey, p)}
+	}
+
+	return nil
+}
+
+// bytesRequestAttr returns an attribute logging the request payload b. If
+// the payload exceeds the size limit then only the fact it was truncated is
+// logged since a partial payload cannot be guaranteed to be valid JSON.
+func bytesRequestAttr(b []byte, truncated bool, limit int) slog.Attr {
+	if truncated {
+		return slog.Group(RequestKey,
+			slog.Bool("truncated", true),
+			slog.Int("limit", limit),
+		)
+	}
+
+	// Payloads that are not JSON objects are logged as plain strings.
+	if !json.Valid(b) {
+		return slog.String(RequestKey, string(b))
+	}
+
+	return slog.Any(RequestKey, byteSliceMarshallable(b))
+}
+
+// captureRequestBody reads at most limit bytes of the request body, replacing
+// the body so that it may still be read in full by the handler. Only the
+// bytes read are held in memory; the remainder is streamed from the original
+// body.
+func captureRequestBody(r *http.Request, limit int) ([]byte, bool, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, false, nil
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+
+	r.Body = &replayedBody{
+		Reader: io.MultiReader(bytes.NewReader(b), r.Body),
+		Closer: r.Body,
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(b) > limit {
+		return b[:limit], true, nil
+	}
+
+	return b, false, nil
+}
+
+// replayedBody is a request body whose captured prefix is read again before
+// the remainder of the original body.
+type replayedBody struct {
+	io.Reader
+	io.Closer
+}
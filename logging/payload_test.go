@@ -0,0 +1,95 @@
+package logging_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kapetndev/connect/logging"
+	"github.com/kapetndev/connect/logging/logtest"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+)
+
+func TestWithRequestPayload(t *testing.T) {
+	t.Parallel()
+
+	t.Run("does not log the request payload by default", func(t *testing.T) {
+		closer, client, h := logtest.NewServer(t, nil)
+		defer closer()
+
+		if _, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: "engage"}); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		r := logtest.AssertLogged(t, h, logtest.Path("/echo.v1.EchoService/Echo"))
+		if v, ok := r.Attr(logging.RequestKey); ok {
+			t.Errorf("request payload was logged: %v", v)
+		}
+	})
+
+	t.Run("logs the payload of a unary request", func(t *testing.T) {
+		closer, client, h := logtest.NewServer(t, nil, logging.WithRequestPayload(1<<10))
+		defer closer()
+
+		if _, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: "engage"}); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		logtest.AssertLogged(t, h,
+			logtest.Path("/echo.v1.EchoService/Echo"),
+			logtest.RequestField("message", "engage"),
+			logtest.Attr(logging.RequestSizeKey, 8),
+		)
+	})
+
+	t.Run("marks a unary request payload exceeding the limit as truncated", func(t *testing.T) {
+		closer, client, h := logtest.NewServer(t, nil, logging.WithRequestPayload(10))
+		defer closer()
+
+		if _, err := client.Echo(context.Background(), &echopb.EchoRequest{Message: "engage"}); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		logtest.AssertLogged(t, h,
+			logtest.Path("/echo.v1.EchoService/Echo"),
+			logtest.RequestField("truncated", true),
+			logtest.RequestField("limit", 10),
+		)
+		logtest.AssertNotLogged(t, h, logtest.RequestField("message", "engage"))
+	})
+
+	t.Run("logs the body of an HTTP request", func(t *testing.T) {
+		_, h := logtest.ServeHTTP(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}, httptest.NewRequest(http.MethodPost, "/bridge", strings.NewReader(`{"captain":"picard"}`)), logging.WithRequestPayload(1<<10))
+
+		logtest.AssertLogged(t, h, logtest.Path("/bridge"), logtest.RequestField("captain", "picard"))
+	})
+
+	t.Run("replays the whole body of an HTTP request exceeding the limit", func(t *testing.T) {
+		body := `{"captain":"picard","ship":"enterprise"}`
+
+		var read string
+		_, h := logtest.ServeHTTP(func(w http.ResponseWriter, r *http.Request) {
+			b, err := io.ReadAll(r.Body)
+			if err != nil {
+				t.Errorf("error was not <nil>: %s", err)
+			}
+			read = string(b)
+			w.WriteHeader(http.StatusNoContent)
+		}, httptest.NewRequest(http.MethodPost, "/bridge", strings.NewReader(body)), logging.WithRequestPayload(10))
+
+		if read != body {
+			t.Errorf("bodies are not equal: %s != %s", read, body)
+		}
+
+		logtest.AssertLogged(t, h,
+			logtest.Path("/bridge"),
+			logtest.RequestField("truncated", true),
+			logtest.RequestField("limit", 10),
+		)
+	})
+}
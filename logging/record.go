@@ -5,13 +5,48 @@ import (
 	"time"
 
 	"golang.org/x/exp/slog"
+
+	"github.com/kapetndev/connect/requestid"
 )
 
+// requestMarker is the value of the attribute marking the records built by
+// newCommonRecord, which describe a request. Attributes such as StatusKey and
+// PathKey are only treated as describing the request on these records, and
+// not when logged by an application. The attribute has an empty key so that
+// it is ignored by the slog handlers.
+type requestMarker struct{}
+
+// requestMarkerAttr is the attribute marking the records of requests.
+var requestMarkerAttr = slog.Any("", requestMarker{})
+
+// isRequestMarker reports whether a is the attribute marking the records of
+// requests.
+func isRequestMarker(a slog.Attr) bool {
+	if a.Key != "" || a.Value.Kind() != slog.KindAny {
+		return false
+	}
+	_, ok := a.Value.Any().(requestMarker)
+	return ok
+}
+
+// isRequestRecord reports whether r describes a request, having been built by
+// newCommonRecord.
+func isRequestRecord(r slog.Record) bool {
+	request := false
+	r.Attrs(func(a slog.Attr) {
+		if isRequestMarker(a) {
+			request = true
+		}
+	})
+	return request
+}
+
 func newCommonRecord(ctx context.Context, level slog.Level, t time.Time, method, path string) slog.Record {
 	duration := time.Since(t)
 
 	record := slog.NewRecord(t, level, "", 0)
 	record.AddAttrs(
+		requestMarkerAttr,
 		slog.Duration(DurationKey, duration),
 		slog.String(MethodKey, method),
 		slog.String(PathKey, path),
@@ -22,5 +57,10 @@ func newCommonRecord(ctx context.Context, level slog.Level, t time.Time, method,
 		record.AddAttrs(slog.Time(DeadlineKey, d))
 	}
 
+	// If the request has been assigned an ID add this to the log entry.
+	if id, ok := requestid.FromContext(ctx); ok {
+		record.AddAttrs(slog.String(RequestIDKey, id))
+	}
+
 	return record
 }
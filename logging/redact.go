@@ -0,0 +1,235 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// DefaultRedactionPlaceholder is the value substituted for redacted fields
+// when no other placeholder has been configured.
+const DefaultRedactionPlaceholder = "[REDACTED]"
+
+// redactor replaces sensitive values within logged payloads. Fields of
+// protobuf messages marked with the debug_redact option are always redacted,
+// in addition to any configured field paths.
+type redactor struct {
+	placeholder string
+	paths       [][]string
+}
+
+// newRedactor returns a redactor for the given field paths. Each path is
+// either a dot separated list of JSON field names, such as "user.password",
+// or a JSON pointer, such as "/user/password". A segment of "*" matches any
+// field name or array element.
+func newRedactor(placeholder string, paths []string) *redactor {
+	r := &redactor{
+		placeholder: placeholder,
+		paths:       make([][]string, 0, len(paths)),
+	}
+
+	for _, p := range paths {
+		if segments := parseRedactionPath(p); len(segments) > 0 {
+			r.paths = append(r.paths, segments)
+		}
+	}
+
+	return r
+}
+
+func parseRedactionPath(p string) []string {
+	if p == "" {
+		return nil
+	}
+
+	// Field paths are separated by dots, whereas JSON pointers begin with a
+	// slash and escape any literal slash or tilde within a segment.
+	if !strings.HasPrefix(p, "/") {
+		return strings.Split(p, ".")
+	}
+
+	segments := strings.Split(p[1:], "/")
+	for i, s := range segments {
+		segments[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(s)
+	}
+
+	return segments
+}
+
+// redactProto returns b, the JSON encoding of m, with all fields marked as
+// debug_redact and all configured paths replaced by the placeholder.
+func (r *redactor) redactProto(m proto.Message, b []byte) []byte {
+	v, ok := decodeJSON(b)
+	if !ok {
+		return b
+	}
+
+	changed := r.redactMessage(proto.MessageReflect(m).Descriptor(), v)
+	if !r.redactPaths(v) && !changed {
+		return b
+	}
+
+	return encodeJSON(v, b)
+}
+
+// redactJSON returns b with all configured paths replaced by the
+// placeholder. Payloads that are not valid JSON are returned unchanged.
+func (r *redactor) redactJSON(b []byte) []byte {
+	if r == nil || len(r.paths) == 0 {
+		return b
+	}
+
+	v, ok := decodeJSON(b)
+	if !ok || !r.redactPaths(v) {
+		return b
+	}
+
+	return encodeJSON(v, b)
+}
+
+// redactMessage walks the decoded JSON object v alongside the descriptor of
+// the message it was encoded from, replacing the value of each field marked
+// as debug_redact. It reports whether any value was replaced.
+func (r *redactor) redactMessage(md protoreflect.MessageDescriptor, v interface{}) bool {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	changed := false
+	fields := md.Fields()
+
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+
+		key := fd.JSONName()
+		value, ok := obj[key]
+		if !ok {
+			key = string(fd.Name())
+			if value, ok = obj[key]; !ok {
+				continue
+			}
+		}
+
+		if opts, ok := fd.Options().(*descriptorpb.FieldOptions); ok && opts.GetDebugRedact() {
+			obj[key] = r.placeholder
+			changed = true
+			continue
+		}
+
+		switch {
+		case fd.IsMap():
+			if vd := fd.MapValue(); vd.Message() != nil {
+				values, _ := value.(map[string]interface{})
+				for _, e := range values {
+					changed = r.redactMessage(vd.Message(), e) || changed
+				}
+			}
+		case fd.Message() == nil:
+			continue
+		case fd.IsList():
+			elems, _ := value.([]interface{})
+			for _, e := range elems {
+				changed = r.redactMessage(fd.Message(), e) || changed
+			}
+		default:
+			changed = r.redactMessage(fd.Message(), value) || changed
+		}
+	}
+
+	return changed
+}
+
+// redactPaths replaces the value at each configured path within v. It
+// reports whether any value was replaced.
+func (r *redactor) redactPaths(v interface{}) bool {
+	changed := false
+	for _, p := range r.paths {
+		changed = r.redactPath(v, p) || changed
+	}
+	return changed
+}
+
+func (r *redactor) redactPath(v interface{}, path []string) bool {
+	segment, last := path[0], len(path) == 1
+	changed := false
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, value := range t {
+			if segment != "*" && segment != key {
+				continue
+			}
+
+			if last {
+				t[key] = r.placeholder
+				changed = true
+				continue
+			}
+
+			changed = r.redactPath(value, path[1:]) || changed
+		}
+	case []interface{}:
+		index, err := strconv.Atoi(segment)
+
+		// A segment that does not address an element of the array is applied
+		// to every element instead, so "users.password" redacts the password
+		// of every user.
+		if segment != "*" && err != nil {
+			for _, e := range t {
+				changed = r.redactPath(e, path) || changed
+			}
+			return changed
+		}
+
+		for i, e := range t {
+			if segment != "*" && i != index {
+				continue
+			}
+
+			if last {
+				t[i] = r.placeholder
+				changed = true
+				continue
+			}
+
+			changed = r.redactPath(e, path[1:]) || changed
+		}
+	}
+
+	return changed
+}
+
+func decodeJSON(b []byte) (interface{}, bool) {
+	var v interface{}
+
+	// Numbers are decoded as json.Number to avoid any loss of precision when
+	// the payload is encoded again.
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	if err := d.Decode(&v); err != nil {
+		return nil, false
+	}
+
+	return v, true
+}
+
+func encodeJSON(v interface{}, fallback []byte) []byte {
+	b := &bytes.Buffer{}
+
+	e := json.NewEncoder(b)
+	e.SetEscapeHTML(false)
+
+	if err := e.Encode(v); err != nil {
+		return fallback
+	}
+
+	return bytes.TrimRight(b.Bytes(), "\n")
+}
@@ -0,0 +1,149 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+// newCredentialsMessage returns a dynamic protobuf message with a password
+// field marked with the debug_redact option.
+func newCredentialsMessage(t *testing.T, username, password string) *dynamicpb.Message {
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("credentials.proto"),
+		Package: proto.String("test.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Credentials"),
+			Field: []*descriptorpb.FieldDescriptorProto{{
+				Name:     proto.String("username"),
+				JsonName: proto.String("username"),
+				Number:   proto.Int32(1),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+			}, {
+				Name:     proto.String("password"),
+				JsonName: proto.String("password"),
+				Number:   proto.Int32(2),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				Options:  &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)},
+			}},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create file descriptor: %s", err)
+	}
+
+	md := fd.Messages().Get(0)
+
+	m := dynamicpb.NewMessage(md)
+	m.Set(md.Fields().ByName("username"), protoreflect.ValueOfString(username))
+	m.Set(md.Fields().ByName("password"), protoreflect.ValueOfString(password))
+
+	return m
+}
+
+func decodeLogEntry(t *testing.T, b []byte) map[string]interface{} {
+	var entry map[string]interface{}
+	if err := json.Unmarshal(b, &entry); err != nil {
+		t.Fatalf("failed to decode log entry: %s", err)
+	}
+	return entry
+}
+
+func TestRedaction_Proto(t *testing.T) {
+	t.Parallel()
+
+	t.Run("redacts fields marked with the debug_redact option", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		interceptor := logging.UnaryServerInterceptor(
+			logging.WithHandler(slog.NewJSONHandler(buf)),
+			logging.WithRequestPayload(1024),
+		)
+
+		req := newCredentialsMessage(t, "picard", "engage")
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return req, nil
+		}
+
+		info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Service/Login"}
+		if _, err := interceptor(context.Background(), req, info, handler); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		entry := decodeLogEntry(t, buf.Bytes())
+
+		for _, key := range []string{logging.RequestKey, logging.ResponseKey} {
+			payload, _ := entry[key].(map[string]interface{})
+
+			if payload["username"] != "picard" {
+				t.Errorf("%s usernames are not equal: %v != %s", key, payload["username"], "picard")
+			}
+
+			if payload["password"] != logging.DefaultRedactionPlaceholder {
+				t.Errorf("%s passwords are not equal: %v != %s", key, payload["password"], logging.DefaultRedactionPlaceholder)
+			}
+		}
+	})
+}
+
+func TestRedaction_HTTP(t *testing.T) {
+	t.Parallel()
+
+	body := `{"user":{"name":"picard","token":"abc"},"items":[{"secret":"x"},{"secret":"y"}],"password":"engage"}`
+
+	t.Run("redacts configured field paths and JSON pointers", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		mw := logging.RequestLogger(
+			logging.WithHandler(slog.NewJSONHandler(buf)),
+			logging.WithRequestPayload(1024),
+			logging.WithRedactedFields("password", "/user/token", "items.secret"),
+			logging.WithRedactionPlaceholder("***"),
+		)
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+
+		mw(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := io.ReadAll(r.Body)
+			w.Write(b)
+		})(w, r)
+
+		if w.Body.String() != body {
+			t.Errorf("bodies are not equal: %s != %s", w.Body.String(), body)
+		}
+
+		entry := decodeLogEntry(t, buf.Bytes())
+
+		expectedPayload := `{"items":[{"secret":"***"},{"secret":"***"}],"password":"***","user":{"name":"picard","token":"***"}}`
+		for _, key := range []string{logging.RequestKey, logging.ResponseKey} {
+			payload, err := json.Marshal(entry[key])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(payload) != expectedPayload {
+				t.Errorf("%s payloads are not equal: %s != %s", key, payload, expectedPayload)
+			}
+		}
+	})
+}
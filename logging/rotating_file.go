@@ -0,0 +1,439 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backupTimeFormat is the format of the time a file was rotated, inserted
+// into the name of the backup between the name and extension of the file.
+const backupTimeFormat = "20060102T150405.000"
+
+// compressSuffix is the suffix of compressed backups.
+const compressSuffix = ".gz"
+
+// RotatingFileOption configures a RotatingFile.
+type RotatingFileOption func(*RotatingFile)
+
+// WithMaxFileSize rotates the file before a write would cause it to exceed
+// size bytes. A size of zero or less disables rotation by size.
+func WithMaxFileSize(size int64) RotatingFileOption {
+	return func(f *RotatingFile) {
+		f.maxSize = size
+	}
+}
+
+// WithRotationInterval rotates the file at the start of each interval, such
+// as every hour or every 24 hours. Intervals are aligned to UTC. An interval
+// of zero or less disables rotation by time.
+func WithRotationInterval(d time.Duration) RotatingFileOption {
+	return func(f *RotatingFile) {
+		f.interval = d
+	}
+}
+
+// WithMaxBackups removes the oldest backups once there are more than n. A
+// value of zero or less keeps every backup.
+func WithMaxBackups(n int) RotatingFileOption {
+	return func(f *RotatingFile) {
+		f.maxBackups = n
+	}
+}
+
+// WithBackupCompression compresses backups using gzip.
+func WithBackupCompression(enabled bool) RotatingFileOption {
+	return func(f *RotatingFile) {
+		f.compress = enabled
+	}
+}
+
+// WithReopenSignals sets the signals upon which the file is reopened, such
+// that it may be rotated by an external tool like logrotate. The default is
+// SIGHUP. Passing no signals disables reopening upon a signal.
+func WithReopenSignals(sigs ...os.Signal) RotatingFileOption {
+	return func(f *RotatingFile) {
+		f.signals = sigs
+	}
+}
+
+// RotatingFile is an io.Writer that writes to a file, rotating it once it
+// reaches a maximum size or at a regular interval, and may be passed to any
+// of the handler constructors. It is safe for concurrent use.
+//
+// When the file is rotated it is renamed to a backup, with the time of the
+// rotation inserted between its name and extension, and a new file is
+// created in its place. Old backups are removed, and compressed, in the
+// background.
+type RotatingFile struct {
+	name       string
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	compress   bool
+	signals    []os.Signal
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	next   time.Time
+	closed bool
+
+	// last is the time of the most recent rotation, ensuring the time in the
+	// name of each backup is unique even when rotated in quick succession.
+	last time.Time
+
+	// Backups are cleaned up by a single goroutine at a time, which waits on
+	// the mill channel for a rotation.
+	mill      chan struct{}
+	sigs      chan os.Signal
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewRotatingFile returns a new RotatingFile writing to the named file, which
+// is created if it does not exist, along with any parent directories.
+func NewRotatingFile(name string, opts ...RotatingFileOption) (*RotatingFile, error) {
+	f := &RotatingFile{
+		name:    name,
+		signals: []os.Signal{syscall.SIGHUP},
+		mill:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	if len(f.signals) > 0 {
+		f.sigs = make(chan os.Signal, 1)
+		signal.Notify(f.sigs, f.signals...)
+	}
+
+	f.wg.Add(1)
+	go f.run()
+
+	return f, nil
+}
+
+// Write writes p to the file, first rotating it if required. A write larger
+// than the maximum size is written in full to a new file. Should the file
+// have been left closed by a failed rotation it is opened once more.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	// Should the file not be renamed it remains open, and is written to
+	// until a later write rotates it successfully.
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil && f.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+// Rotate rotates the file immediately.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	return f.rotate()
+}
+
+// Reopen closes the file and opens it once more by name, creating it if it
+// has been moved or removed.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+
+	if err := f.closeFile(); err != nil {
+		return err
+	}
+
+	return f.open()
+}
+
+// Close closes the file and waits for any backups to be cleaned up.
+func (f *RotatingFile) Close() error {
+	// The goroutine is stopped before the lock is taken since it may itself
+	// be waiting on the lock to reopen the file.
+	f.closeOnce.Do(func() {
+		if f.sigs != nil {
+			signal.Stop(f.sigs)
+		}
+		close(f.done)
+	})
+	f.wg.Wait()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return os.ErrClosed
+	}
+	f.closed = true
+
+	return f.closeFile()
+}
+
+// shouldRotate reports whether the file must be rotated before n bytes are
+// written. The caller must hold the lock.
+func (f *RotatingFile) shouldRotate(n int64) bool {
+	if f.maxSize > 0 && f.size > 0 && f.size+n > f.maxSize {
+		return true
+	}
+	return f.interval > 0 && !time.Now().Before(f.next)
+}
+
+// closeFile closes the file, if open. The file is forgotten even if closing it
+// fails, since it may not be written to again. The caller must hold the lock.
+func (f *RotatingFile) closeFile() error {
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
+
+// open opens the file for appending. The caller must hold the lock.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.name), 0o755); err != nil {
+		return fmt.Errorf("logging: failed to create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(f.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("logging: failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("logging: failed to open log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+
+	if f.interval > 0 {
+		f.next = time.Now().UTC().Truncate(f.interval).Add(f.interval)
+	}
+
+	return nil
+}
+
+// rotate renames the file to a backup and opens a new file in its place. If
+// the file cannot be renamed it is opened once more by name, so that writes
+// may continue without rotating. Should it not be possible to open the file,
+// it is left closed and opened by the next write. The caller must hold the
+// lock.
+func (f *RotatingFile) rotate() error {
+	if err := f.closeFile(); err != nil {
+		return err
+	}
+
+	t := time.Now().UTC().Truncate(time.Millisecond)
+	if !t.After(f.last) {
+		t = f.last.Add(time.Millisecond)
+	}
+	f.last = t
+
+	backup := f.backupName(t)
+	if err := os.Rename(f.name, backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		_ = f.open()
+		return fmt.Errorf("logging: failed to rotate log file: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	// Signal the goroutine to clean up the backups, unless it has already
+	// been signalled.
+	select {
+	case f.mill <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// backupName returns the name of the backup of the file rotated at t.
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.name)
+	prefix := strings.TrimSuffix(f.name, ext)
+	return prefix + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
+
+// run cleans up backups after each rotation and reopens the file upon a
+// signal, until the file is closed.
+func (f *RotatingFile) run() {
+	defer f.wg.Done()
+
+	for {
+		select {
+		case <-f.mill:
+			f.cleanup()
+		case <-f.sigs:
+			_ = f.Reopen()
+		case <-f.done:
+			// Clean up after a rotation that raced with closing the file.
+			select {
+			case <-f.mill:
+				f.cleanup()
+			default:
+			}
+			return
+		}
+	}
+}
+
+// cleanup compresses backups, if enabled, and removes the oldest backups
+// beyond the maximum. Errors are ignored since there is nowhere to report
+// them; the backups are left to be cleaned up after the next rotation.
+func (f *RotatingFile) cleanup() {
+	backups, err := f.backups()
+	if err != nil {
+		return
+	}
+
+	if f.maxBackups > 0 && len(backups) > f.maxBackups {
+		for _, name := range backups[:len(backups)-f.maxBackups] {
+			_ = os.Remove(name)
+		}
+		backups = backups[len(backups)-f.maxBackups:]
+	}
+
+	if !f.compress {
+		return
+	}
+
+	for _, name := range backups {
+		if !strings.HasSuffix(name, compressSuffix) {
+			_ = compressFile(name)
+		}
+	}
+}
+
+// backups returns the names of the backups of the file, oldest first.
+func (f *RotatingFile) backups() ([]string, error) {
+	ext := filepath.Ext(f.name)
+	prefix := strings.TrimSuffix(f.name, ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(f.name))
+	if err != nil {
+		return nil, err
+	}
+
+	type backup struct {
+		name string
+		t    time.Time
+	}
+
+	var backups []backup
+	for _, e := range entries {
+		name := filepath.Join(filepath.Dir(f.name), e.Name())
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		ts := strings.TrimPrefix(strings.TrimSuffix(name, compressSuffix), prefix)
+		if !strings.HasSuffix(ts, ext) {
+			continue
+		}
+
+		t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(ts, ext))
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backup{name: name, t: t})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].t.Before(backups[j].t)
+	})
+
+	names := make([]string, len(backups))
+	for i, b := range backups {
+		names[i] = b.name
+	}
+
+	return names, nil
+}
+
+// compressFile compresses the named file using gzip, removing the original
+// once the compressed file has been written.
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	// Remove the partially written file should compression fail.
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(name + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
@@ -0,0 +1,302 @@
+package logging_test
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+// logFiles returns the names of the files in dir, sorted.
+func logFiles(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func readFile(t *testing.T, name string) string {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestRotatingFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rotates the file before it exceeds the maximum size", func(t *testing.T) {
+		dir := t.TempDir()
+		name := filepath.Join(dir, "bridge.log")
+
+		f, err := logging.NewRotatingFile(name, logging.WithMaxFileSize(10))
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		for _, s := range []string{"engage\n", "make it so\n", "hailing\n"} {
+			if _, err := f.Write([]byte(s)); err != nil {
+				t.Fatalf("error was not <nil>: %s", err)
+			}
+		}
+
+		if err := f.Close(); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		files := logFiles(t, dir)
+		if len(files) != 3 {
+			t.Fatalf("files are not equal: %v", files)
+		}
+
+		for i, want := range []string{"engage\n", "make it so\n"} {
+			if !strings.HasPrefix(files[i], "bridge-") || !strings.HasSuffix(files[i], ".log") {
+				t.Errorf("backup was not named after the file: %s", files[i])
+			}
+			if got := readFile(t, filepath.Join(dir, files[i])); got != want {
+				t.Errorf("contents are not equal: %q != %q", got, want)
+			}
+		}
+
+		if got := readFile(t, name); got != "hailing\n" {
+			t.Errorf("contents are not equal: %q != %q", got, "hailing\n")
+		}
+	})
+
+	t.Run("rotates the file at the start of each interval", func(t *testing.T) {
+		dir := t.TempDir()
+		name := filepath.Join(dir, "bridge.log")
+
+		f, err := logging.NewRotatingFile(name, logging.WithRotationInterval(50*time.Millisecond))
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		defer f.Close()
+
+		f.Write([]byte("engage\n"))
+
+		// Wait until the start of the next interval, which is aligned to UTC.
+		next := time.Now().UTC().Truncate(50 * time.Millisecond).Add(50 * time.Millisecond)
+		for time.Now().Before(next) {
+			time.Sleep(time.Millisecond)
+		}
+
+		f.Write([]byte("make it so\n"))
+
+		// The file may also have been rotated before the first write, should
+		// an interval have started in between.
+		if files := logFiles(t, dir); len(files) < 2 {
+			t.Fatalf("file was not rotated: %v", files)
+		}
+		if got := readFile(t, name); got != "make it so\n" {
+			t.Errorf("contents are not equal: %q != %q", got, "make it so\n")
+		}
+	})
+
+	t.Run("removes the oldest backups beyond the maximum and compresses the rest", func(t *testing.T) {
+		dir := t.TempDir()
+		name := filepath.Join(dir, "bridge.log")
+
+		f, err := logging.NewRotatingFile(name, logging.WithMaxBackups(2), logging.WithBackupCompression(true))
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		for _, s := range []string{"engage\n", "make it so\n", "hailing\n", "red alert\n"} {
+			f.Write([]byte(s))
+			if err := f.Rotate(); err != nil {
+				t.Fatalf("error was not <nil>: %s", err)
+			}
+		}
+
+		if err := f.Close(); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		files := logFiles(t, dir)
+		if len(files) != 3 {
+			t.Fatalf("files are not equal: %v", files)
+		}
+
+		for i, want := range []string{"hailing\n", "red alert\n"} {
+			if !strings.HasSuffix(files[i], ".log.gz") {
+				t.Fatalf("backup was not compressed: %s", files[i])
+			}
+
+			file, err := os.Open(filepath.Join(dir, files[i]))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				t.Fatalf("error was not <nil>: %s", err)
+			}
+
+			b, _ := io.ReadAll(gz)
+			if string(b) != want {
+				t.Errorf("contents are not equal: %q != %q", b, want)
+			}
+		}
+	})
+
+	t.Run("reopens the file once moved", func(t *testing.T) {
+		dir := t.TempDir()
+		name := filepath.Join(dir, "bridge.log")
+
+		f, err := logging.NewRotatingFile(name, logging.WithReopenSignals())
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		defer f.Close()
+
+		f.Write([]byte("engage\n"))
+		if err := os.Rename(name, name+".1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := f.Reopen(); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		f.Write([]byte("make it so\n"))
+
+		if got := readFile(t, name); got != "make it so\n" {
+			t.Errorf("contents are not equal: %q != %q", got, "make it so\n")
+		}
+	})
+
+	t.Run("opens the file once more after a failed rotation", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "logs")
+		name := filepath.Join(dir, "bridge.log")
+
+		f, err := logging.NewRotatingFile(name, logging.WithReopenSignals())
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		defer f.Close()
+
+		f.Write([]byte("engage\n"))
+
+		// Replace the directory with a file, such that the file may neither
+		// be renamed nor created.
+		if err := os.Rename(dir, dir+".1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(dir, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := f.Rotate(); err == nil {
+			t.Fatal("error was <nil>")
+		}
+		if _, err := f.Write([]byte("make it so\n")); err == nil {
+			t.Fatal("error was <nil>")
+		}
+
+		if err := os.Remove(dir); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte("hailing\n")); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		if got := readFile(t, name); got != "hailing\n" {
+			t.Errorf("contents are not equal: %q != %q", got, "hailing\n")
+		}
+	})
+
+	t.Run("reopens the file upon SIGHUP", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("signals are not supported on windows")
+		}
+
+		dir := t.TempDir()
+		name := filepath.Join(dir, "bridge.log")
+
+		f, err := logging.NewRotatingFile(name)
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		defer f.Close()
+
+		if err := os.Rename(name, name+".1"); err != nil {
+			t.Fatal(err)
+		}
+
+		p, _ := os.FindProcess(os.Getpid())
+		if err := p.Signal(syscall.SIGHUP); err != nil {
+			t.Fatal(err)
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if _, err := os.Stat(name); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("file was not reopened")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("is safe for concurrent writes by a handler", func(t *testing.T) {
+		dir := t.TempDir()
+		name := filepath.Join(dir, "bridge.log")
+
+		f, err := logging.NewRotatingFile(name, logging.WithMaxFileSize(1024), logging.WithReopenSignals())
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		logger := logging.New(logging.NewGoogleCloudHandler(f, logging.LevelInfo))
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					logger.Info(context.Background(), "engage")
+				}
+			}()
+		}
+		wg.Wait()
+
+		if err := f.Close(); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		lines := 0
+		for _, file := range logFiles(t, dir) {
+			for _, line := range strings.Split(strings.TrimSpace(readFile(t, filepath.Join(dir, file))), "\n") {
+				decodeLogEntry(t, []byte(line))
+				lines++
+			}
+		}
+
+		if lines != 500 {
+			t.Errorf("lines are not equal: %d != %d", lines, 500)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"context"
+	"math/rand"
+	"path"
+
+	"golang.org/x/exp/slog"
+)
+
+// rule holds the options applied to requests matching a pattern.
+type rule struct {
+	pattern string
+	opts    []Option
+}
+
+// forRoute returns the options to use for the request to the given gRPC full
+// method or HTTP path, having applied the options of every matching rule.
+func (o options) forRoute(route string) options {
+	rules := o.rules
+	o.rules = nil // Rules may not be nested.
+
+	// Clip the slices options may append to, so that the backing arrays
+	// shared between requests are never written.
+	o.redactPaths = o.redactPaths[:len(o.redactPaths):len(o.redactPaths)]
+
+	matched := false
+	for _, r := range rules {
+		if ok, _ := path.Match(r.pattern, route); !ok {
+			continue
+		}
+
+		for _, opt := range r.opts {
+			opt(&o)
+		}
+		matched = true
+	}
+
+	if matched {
+		o.redactor = newRedactor(o.redactPlaceholder, o.redactPaths)
+	}
+
+	return o
+}
+
+// sampled reports whether a request should be logged given the sample rate.
+func (o options) sampled() bool {
+	return o.sampleRate >= 1 || rand.Float64() < o.sampleRate
+}
+
+// handle writes the request log entry record, unless it is below the minimum
+// level.
+func (o options) handle(ctx context.Context, record slog.Record) {
+	if o.minLevel != nil && record.Level < o.minLevel.Level() {
+		return
+	}
+
+	o.handler.Handle(ctx, record)
+}
@@ -0,0 +1,128 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/kapetndev/connect/logging"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+)
+
+func TestWithRule_Interceptor(t *testing.T) {
+	t.Parallel()
+
+	invoke := func(t *testing.T, method string, err error, opts ...logging.Option) *bytes.Buffer {
+		buf := &bytes.Buffer{}
+		interceptor := logging.UnaryServerInterceptor(append([]logging.Option{logging.WithHandler(slog.NewJSONHandler(buf))}, opts...)...)
+
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			if err != nil {
+				return nil, err
+			}
+			return &echopb.EchoResponse{Message: "engage"}, nil
+		}
+
+		info := &grpc.UnaryServerInfo{FullMethod: method}
+		_, _ = interceptor(context.Background(), &echopb.EchoRequest{Message: "engage"}, info, handler)
+
+		return buf
+	}
+
+	healthRule := logging.WithRule("/grpc.health.v1.Health/*", logging.WithMinLevel(logging.LevelWarning))
+
+	t.Run("discards entries below the minimum level of a matching rule", func(t *testing.T) {
+		if buf := invoke(t, "/grpc.health.v1.Health/Check", nil, healthRule); buf.Len() != 0 {
+			t.Errorf("entry was written: %s", buf.Bytes())
+		}
+	})
+
+	t.Run("writes entries at or above the minimum level of a matching rule", func(t *testing.T) {
+		err := status.Error(codes.Unavailable, "not serving")
+		if buf := invoke(t, "/grpc.health.v1.Health/Check", err, healthRule); buf.Len() == 0 {
+			t.Error("entry was not written")
+		}
+	})
+
+	t.Run("does not apply rules to methods that do not match", func(t *testing.T) {
+		if buf := invoke(t, "/echo.v1.EchoService/Echo", nil, healthRule); buf.Len() == 0 {
+			t.Error("entry was not written")
+		}
+	})
+
+	t.Run("turns payload capture off for matching methods", func(t *testing.T) {
+		buf := invoke(t, "/echo.v1.EchoService/Echo", nil,
+			logging.WithRequestPayload(1024),
+			logging.WithRule("/echo.v1.EchoService/*", logging.WithPayloadCapture(false)),
+		)
+
+		entry := decodeLogEntry(t, buf.Bytes())
+
+		for _, key := range []string{logging.RequestKey, logging.ResponseKey} {
+			if _, ok := entry[key]; ok {
+				t.Errorf("%s was written", key)
+			}
+		}
+
+		if _, ok := entry[logging.ResponseSizeKey]; !ok {
+			t.Error("response size was not written")
+		}
+	})
+
+	t.Run("turns payload capture on for matching methods", func(t *testing.T) {
+		buf := invoke(t, "/echo.v1.EchoService/Echo", nil,
+			logging.WithPayloadCapture(false),
+			logging.WithRule("/echo.v1.EchoService/Echo", logging.WithPayloadCapture(true)),
+		)
+
+		entry := decodeLogEntry(t, buf.Bytes())
+
+		for _, key := range []string{logging.RequestKey, logging.ResponseKey} {
+			if _, ok := entry[key]; !ok {
+				t.Errorf("%s was not written", key)
+			}
+		}
+	})
+
+	t.Run("samples entries at the rate of a matching rule", func(t *testing.T) {
+		if buf := invoke(t, "/echo.v1.EchoService/Echo", nil, logging.WithRule("/echo.v1.*/*", logging.WithSampleRate(0))); buf.Len() != 0 {
+			t.Errorf("entry was written: %s", buf.Bytes())
+		}
+	})
+}
+
+func TestWithRule_RequestLogger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("applies rules matching the request path", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		mw := logging.RequestLogger(
+			logging.WithHandler(slog.NewJSONHandler(buf)),
+			logging.WithRule("/healthz", logging.WithSampleRate(0)),
+		)
+
+		handler := mw(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"status":"ok"}`))
+		})
+
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		if buf.Len() != 0 {
+			t.Errorf("entry was written: %s", buf.Bytes())
+		}
+
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bridge", strings.NewReader("")))
+		if buf.Len() == 0 {
+			t.Error("entry was not written")
+		}
+	})
+}
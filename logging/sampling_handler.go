@@ -0,0 +1,246 @@
+package logging
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Attributes of the summary record written by a SamplingHandler.
+const (
+	samplingDroppedKey  = "dropped"
+	samplingIntervalKey = "interval"
+	samplingSummaryMsg  = "log records dropped by sampling"
+)
+
+// SamplingHandler is a handler that limits the volume of log records passed
+// to another handler. Within each interval the first records sharing a key
+// are handled, after which only every thereafter-th record is handled.
+// Records are keyed by their message along with the values of their MethodKey
+// and PathKey attributes, so that requests to each method are sampled apart.
+// Records at the error level or above are never dropped.
+//
+// Intervals are measured by the clock of the handler rather than the time of
+// each record. The number of records dropped during an interval is reported
+// by a summary record, written at the warning level once the interval has
+// ended, even if no further records are handled. Close reports any records
+// dropped during the current interval and stops the goroutine writing the
+// summaries.
+type SamplingHandler struct {
+	handler slog.Handler
+	sampler *sampler
+}
+
+// sampler holds the state shared by a SamplingHandler and the handlers
+// derived from it.
+type sampler struct {
+	// handler is the handler the SamplingHandler was created with, without
+	// any attributes or groups added since, to which summaries are written.
+	handler    slog.Handler
+	interval   time.Duration
+	first      int
+	thereafter int
+
+	mu      sync.Mutex
+	now     func() time.Time
+	end     time.Time
+	counts  map[samplingKey]int
+	dropped int64
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+type samplingKey struct {
+	message string
+	method  string
+	path    string
+}
+
+// NewSamplingHandler returns a new SamplingHandler passing sampled records to
+// h. Within each interval the first records sharing a key are handled, then
+// every thereafter-th record. If thereafter is less than one all records
+// beyond the first are dropped. If interval is not positive the records are
+// counted over the lifetime of the handler, with the records dropped reported
+// only when it is closed.
+func NewSamplingHandler(h slog.Handler, interval time.Duration, first, thereafter int) *SamplingHandler {
+	s := &sampler{
+		handler:    h,
+		interval:   interval,
+		first:      first,
+		thereafter: thereafter,
+		now:        time.Now,
+		counts:     make(map[samplingKey]int),
+		done:       make(chan struct{}),
+	}
+
+	if interval > 0 {
+		s.wg.Add(1)
+		go s.run()
+	}
+
+	return &SamplingHandler{handler: h, sampler: s}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle passes r to the underlying handler if it is sampled, otherwise it is
+// dropped.
+func (h *SamplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	summary, ok := h.sampler.sample(r)
+
+	if summary != nil {
+		if err := h.sampler.handler.Handle(ctx, *summary); err != nil {
+			return err
+		}
+	}
+
+	if !ok {
+		return nil
+	}
+
+	return h.handler.Handle(ctx, r)
+}
+
+// Close writes a summary of the records dropped during the current interval,
+// if any, and stops writing summaries at the end of each interval. It closes
+// the handlers derived from h, with which it shares its sampling state.
+func (h *SamplingHandler) Close(ctx context.Context) error {
+	s := h.sampler
+
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+
+	s.mu.Lock()
+	summary := s.summary(s.now())
+	s.dropped = 0
+	s.mu.Unlock()
+
+	if summary == nil {
+		return nil
+	}
+
+	return s.handler.Handle(ctx, *summary)
+}
+
+// WithAttrs returns a new SamplingHandler whose attributes consists of h's
+// attributes followed by attrs. The new handler shares the sampling state of
+// h.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &SamplingHandler{handler: h.handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+// WithGroup returns a new SamplingHandler whose attributes consists of h's
+// attributes followed by a group with the given name. The new handler shares
+// the sampling state of h.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	return &SamplingHandler{handler: h.handler.WithGroup(name), sampler: h.sampler}
+}
+
+// sample reports whether r should be handled. When r is the first record of
+// a new interval, and records were dropped during the previous interval, it
+// also returns a record summarising the number dropped.
+func (s *sampler) sample(r slog.Record) (*slog.Record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summary := s.advance(s.now())
+
+	if r.Level >= LevelError {
+		return summary, true
+	}
+
+	key := samplingKey{message: r.Message}
+	r.Attrs(func(a slog.Attr) {
+		switch a.Key {
+		case MethodKey:
+			key.method = a.Value.String()
+		case PathKey:
+			key.path = a.Value.String()
+		}
+	})
+
+	s.counts[key]++
+	n := s.counts[key]
+
+	if n <= s.first || (s.thereafter > 0 && (n-s.first)%s.thereafter == 0) {
+		return summary, true
+	}
+
+	s.dropped++
+	return summary, false
+}
+
+// run writes a summary of the records dropped during each interval once it
+// has ended, so that they are reported even if no further records are
+// handled, until the handler is closed. The timer is set for the end of the
+// current interval, which may have been started by a record since it was
+// last set.
+func (s *sampler) run() {
+	defer s.wg.Done()
+
+	timer := time.NewTimer(s.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			s.mu.Lock()
+			now := s.now()
+			summary := s.advance(now)
+			next := s.end.Sub(now)
+			s.mu.Unlock()
+
+			if summary != nil {
+				_ = s.handler.Handle(context.Background(), *summary)
+			}
+
+			timer.Reset(next)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// advance starts a new interval if the current interval has ended by now,
+// returning a summary of the records dropped during the interval, if any.
+// Without a positive interval the current interval never ends. The caller
+// must hold the lock.
+func (s *sampler) advance(now time.Time) *slog.Record {
+	if s.interval <= 0 || now.Before(s.end) {
+		return nil
+	}
+
+	summary := s.summary(now)
+
+	s.end = now.Add(s.interval)
+	s.counts = make(map[samplingKey]int)
+	s.dropped = 0
+
+	return summary
+}
+
+// summary returns a record summarising the number of records dropped during
+// the current interval, or nil if none were dropped. The caller must hold the
+// lock.
+func (s *sampler) summary(now time.Time) *slog.Record {
+	if s.dropped == 0 {
+		return nil
+	}
+
+	record := slog.NewRecord(now, LevelWarning, samplingSummaryMsg, 0)
+	record.AddAttrs(
+		slog.Int64(samplingDroppedKey, s.dropped),
+		slog.Duration(samplingIntervalKey, s.interval),
+	)
+
+	return &record
+}
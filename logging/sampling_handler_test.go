@@ -0,0 +1,212 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/kapetndev/connect/logging"
+	"github.com/kapetndev/connect/logging/logtest"
+)
+
+func decodeLogEntries(t *testing.T, b []byte) []map[string]interface{} {
+	var entries []map[string]interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	for dec.More() {
+		var entry map[string]interface{}
+		if err := dec.Decode(&entry); err != nil {
+			t.Fatalf("failed to decode log entry: %s", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func TestSamplingHandler(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	handle := func(h slog.Handler, t time.Time, level slog.Level, msg string, attrs ...slog.Attr) {
+		r := slog.NewRecord(t, level, msg, 0)
+		r.AddAttrs(attrs...)
+		_ = h.Handle(context.Background(), r)
+	}
+
+	t.Run("handles the first records then one in every thereafter", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := logging.NewSamplingHandler(slog.NewJSONHandler(buf), time.Hour, 2, 3)
+
+		for i := 0; i < 10; i++ {
+			handle(h, start, logging.LevelInfo, "beam me up", slog.Int("i", i))
+		}
+
+		var handled []float64
+		for _, entry := range decodeLogEntries(t, buf.Bytes()) {
+			handled = append(handled, entry["i"].(float64))
+		}
+
+		assertJSON(t, "handled records", handled, `[0,1,4,7]`)
+	})
+
+	t.Run("samples each method and path apart", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := logging.NewSamplingHandler(slog.NewJSONHandler(buf), time.Hour, 1, 0)
+
+		for _, path := range []string{"/bridge", "/engineering", "/bridge"} {
+			handle(h, start, logging.LevelInfo, "", slog.String(logging.MethodKey, "GET"), slog.String(logging.PathKey, path))
+		}
+
+		if n := len(decodeLogEntries(t, buf.Bytes())); n != 2 {
+			t.Errorf("numbers of records are not equal: %d != %d", n, 2)
+		}
+	})
+
+	t.Run("never drops records at the error level or above", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := logging.NewSamplingHandler(slog.NewJSONHandler(buf), time.Hour, 0, 0)
+
+		for i := 0; i < 3; i++ {
+			handle(h, start, logging.LevelError, "red alert")
+		}
+
+		if n := len(decodeLogEntries(t, buf.Bytes())); n != 3 {
+			t.Errorf("numbers of records are not equal: %d != %d", n, 3)
+		}
+	})
+
+	t.Run("reports the records dropped once the interval has ended", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		sh := logging.NewSamplingHandler(slog.NewJSONHandler(buf), time.Hour, 1, 0)
+		defer sh.Close(context.Background())
+
+		now := start
+		logging.SetSamplingClock(sh, func() time.Time { return now })
+
+		h := sh.WithGroup("crew")
+		for i := 0; i < 5; i++ {
+			handle(h, start, logging.LevelInfo, "beam me up")
+		}
+
+		now = start.Add(time.Hour)
+		handle(h, now, logging.LevelInfo, "beam me up")
+
+		entries := decodeLogEntries(t, buf.Bytes())
+		if len(entries) != 3 {
+			t.Fatalf("numbers of records are not equal: %d != %d", len(entries), 3)
+		}
+
+		summary := entries[1]
+		if summary["level"] != "WARN" {
+			t.Errorf("levels are not equal: %v != %s", summary["level"], "WARN")
+		}
+
+		if summary["dropped"] != float64(4) {
+			t.Errorf("numbers of dropped records are not equal: %v != %d", summary["dropped"], 4)
+		}
+	})
+
+	t.Run("measures intervals by its clock rather than the time of each record", func(t *testing.T) {
+		capture := logtest.NewHandler(nil)
+		h := logging.NewSamplingHandler(capture, time.Hour, 1, 0)
+		defer h.Close(context.Background())
+
+		logging.SetSamplingClock(h, func() time.Time { return start })
+
+		for i := 0; i < 3; i++ {
+			handle(h, start.Add(time.Duration(i)*time.Hour), logging.LevelInfo, "beam me up")
+		}
+
+		if n := len(capture.Records()); n != 1 {
+			t.Errorf("numbers of records are not equal: %d != %d", n, 1)
+		}
+	})
+
+	t.Run("counts records over its lifetime without a positive interval", func(t *testing.T) {
+		capture := logtest.NewHandler(nil)
+		h := logging.NewSamplingHandler(capture, 0, 1, 0)
+
+		now := start
+		logging.SetSamplingClock(h, func() time.Time { return now })
+
+		for i := 0; i < 3; i++ {
+			handle(h, now, logging.LevelInfo, "beam me up")
+			now = now.Add(time.Hour)
+		}
+
+		if n := len(capture.Records()); n != 1 {
+			t.Fatalf("numbers of records are not equal: %d != %d", n, 1)
+		}
+
+		if err := h.Close(context.Background()); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		records := capture.Records()
+		if len(records) != 2 {
+			t.Fatalf("numbers of records are not equal: %d != %d", len(records), 2)
+		}
+		if dropped, _ := records[1].Attr("dropped"); dropped != int64(2) {
+			t.Errorf("numbers of dropped records are not equal: %v != %d", dropped, 2)
+		}
+	})
+
+	t.Run("reports the records dropped once no further records are handled", func(t *testing.T) {
+		capture := logtest.NewHandler(nil)
+		h := logging.NewSamplingHandler(capture, 10*time.Millisecond, 1, 0)
+		defer h.Close(context.Background())
+
+		for i := 0; i < 5; i++ {
+			handle(h, time.Now(), logging.LevelInfo, "beam me up")
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for len(capture.Records()) < 2 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+
+		records := capture.Records()
+		if len(records) != 2 {
+			t.Fatalf("numbers of records are not equal: %d != %d", len(records), 2)
+		}
+
+		if dropped, _ := records[1].Attr("dropped"); dropped != int64(4) {
+			t.Errorf("numbers of dropped records are not equal: %v != %d", dropped, 4)
+		}
+	})
+
+	t.Run("reports the records dropped during the current interval when closed", func(t *testing.T) {
+		capture := logtest.NewHandler(nil)
+		h := logging.NewSamplingHandler(capture, time.Hour, 1, 0)
+
+		for i := 0; i < 3; i++ {
+			handle(h, time.Now(), logging.LevelInfo, "beam me up")
+		}
+
+		if err := h.Close(context.Background()); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		records := capture.Records()
+		if len(records) != 2 {
+			t.Fatalf("numbers of records are not equal: %d != %d", len(records), 2)
+		}
+
+		if dropped, _ := records[1].Attr("dropped"); dropped != int64(2) {
+			t.Errorf("numbers of dropped records are not equal: %v != %d", dropped, 2)
+		}
+
+		if err := h.Close(context.Background()); err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		if n := len(capture.Records()); n != 2 {
+			t.Errorf("summary was written again: %d records", n)
+		}
+	})
+}
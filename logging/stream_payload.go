@@ -0,0 +1,202 @@
+package logging
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Directions of a message captured from a stream.
+const (
+	streamDirectionRecv = "recv"
+	streamDirectionSend = "send"
+)
+
+// streamMessage is a single message captured from a stream.
+type streamMessage struct {
+	Direction string          `json:"direction"`
+	Sequence  int             `json:"sequence"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// streamPayload collects the messages sent and received on a stream, up to a
+// maximum number of messages and bytes. Once either limit is reached all
+// subsequent messages are dropped and the payload is marked as truncated.
+type streamPayload struct {
+	maxMessages int
+	maxBytes    int
+	redactor    *redactor
+
+	mu        sync.Mutex
+	messages  []streamMessage
+	size      int
+	truncated bool
+}
+
+func newStreamPayload(maxMessages, maxBytes int, r *redactor) *streamPayload {
+	return &streamPayload{
+		maxMessages: maxMessages,
+		maxBytes:    maxBytes,
+		redactor:    r,
+	}
+}
+
+// add captures the message m as JSON. The message is serialized immediately
+// since gRPC may reuse the underlying value once the call has returned. A nil
+// payload captures nothing.
+func (p *streamPayload) add(direction string, sequence int, m interface{}) {
+	if p == nil {
+		return
+	}
+
+	pbMsg, ok := m.(proto.Message)
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.truncated {
+		return
+	}
+
+	b, err := (&jsonpbMarshalleble{Message: pbMsg, redactor: p.redactor}).MarshalJSON()
+	if err != nil {
+		return
+	}
+
+	if len(p.messages) >= p.maxMessages || p.size+len(b) > p.maxBytes {
+		p.truncated = true
+		return
+	}
+
+	p.size += len(b)
+	p.messages = append(p.messages, streamMessage{
+		Direction: direction,
+		Sequence:  sequence,
+		Message:   b,
+	})
+}
+
+// empty reports whether no messages were captured.
+func (p *streamPayload) empty() bool {
+	if p == nil {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.messages) == 0 && !p.truncated
+}
+
+// MarshalJSON handles generating a slice of bytes representing the captured
+// messages as JSON.
+func (p *streamPayload) MarshalJSON() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return json.Marshal(struct {
+		Messages  []streamMessage `json:"messages"`
+		Truncated bool            `json:"truncated,omitempty"`
+	}{
+		Messages:  p.messages,
+		Truncated: p.truncated,
+	})
+}
+
+// streamCapture captures the payload of every message sent and received on
+// either side of a stream. Requests and responses may be captured by the same
+// payload or by separate payloads; either way sequence numbers reflect the
+// order of messages across the whole stream.
+type streamCapture struct {
+	// Total size of the requests and responses. These are accessed
+	// atomically and so must remain 64-bit aligned.
+	requestSize  int64
+	responseSize int64
+
+	requests  *streamPayload
+	responses *streamPayload
+	sequence  int32
+}
+
+// newStreamCapture returns a capture of the payloads of a stream as enabled
+// by o. Requests are only kept apart from responses when request payloads
+// are logged. Nothing is captured from streams that will not be logged.
+func newStreamCapture(o options, sampled bool) *streamCapture {
+	c := &streamCapture{}
+
+	if sampled && o.logResponses {
+		c.responses = newStreamPayload(o.maxStreamMessages, o.maxStreamBytes, o.redactor)
+	}
+
+	c.requests = c.responses
+	if sampled && o.logRequests {
+		c.requests = newStreamPayload(o.maxStreamMessages, o.maxRequestBytes, o.redactor)
+	}
+
+	return c
+}
+
+// request captures a request sent or received in the given direction.
+func (c *streamCapture) request(direction string, m interface{}) {
+	atomic.AddInt64(&c.requestSize, messageSize(m))
+	c.requests.add(direction, c.next(), m)
+}
+
+// response captures a response sent or received in the given direction.
+func (c *streamCapture) response(direction string, m interface{}) {
+	atomic.AddInt64(&c.responseSize, messageSize(m))
+	c.responses.add(direction, c.next(), m)
+}
+
+// sizeAttrs returns the attributes logging the total size of the requests
+// and responses of the stream.
+func (c *streamCapture) sizeAttrs() []slog.Attr {
+	return []slog.Attr{
+		slog.Int64(RequestSizeKey, atomic.LoadInt64(&c.requestSize)),
+		slog.Int64(ResponseSizeKey, atomic.LoadInt64(&c.responseSize)),
+	}
+}
+
+func (c *streamCapture) next() int {
+	return int(atomic.AddInt32(&c.sequence, 1))
+}
+
+// payloadServerStream wraps a server side stream capturing the payload of
+// every message received as a request and every message sent as a response.
+type payloadServerStream struct {
+	*streamCapture
+	grpc.ServerStream
+}
+
+// SendMsg sends a message, capturing its payload on success.
+func (ss *payloadServerStream) SendMsg(m interface{}) error {
+	err := ss.ServerStream.SendMsg(m)
+	if err == nil {
+		ss.response(streamDirectionSend, m)
+	}
+	return err
+}
+
+// RecvMsg receives a message, capturing its payload on success.
+func (ss *payloadServerStream) RecvMsg(m interface{}) error {
+	err := ss.ServerStream.RecvMsg(m)
+	if err == nil {
+		ss.request(streamDirectionRecv, m)
+	}
+	return err
+}
+
+func messageSize(m interface{}) int64 {
+	if pbMsg, ok := m.(proto.Message); ok {
+		return int64(proto.Size(pbMsg))
+	}
+	return 0
+}
@@ -0,0 +1,124 @@
+package logging_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/kapetndev/connect/logging"
+	"github.com/kapetndev/connect/logging/logtest"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+)
+
+// streamPayload returns the direction and sequence number of each message
+// captured in the payload under key, along with whether it was truncated.
+func streamPayload(t *testing.T, r logtest.Record, key string) ([]string, bool) {
+	t.Helper()
+
+	payload, ok := r.Attr(key)
+	if !ok {
+		return nil, false
+	}
+
+	p, ok := payload.(map[string]any)
+	if !ok {
+		t.Fatalf("payload is not an object: %v", payload)
+	}
+
+	messages, _ := p["messages"].([]any)
+	got := make([]string, 0, len(messages))
+	for _, m := range messages {
+		m := m.(map[string]any)
+		got = append(got, fmt.Sprintf("%s:%v", m["direction"], m["sequence"]))
+	}
+
+	truncated, _ := p["truncated"].(bool)
+	return got, truncated
+}
+
+func TestWithStreamPayloadLimit(t *testing.T) {
+	t.Parallel()
+
+	// Each message is encoded as {"message":"engage"}, which is 20 bytes.
+	tests := []struct {
+		name              string
+		opts              []logging.Option
+		wantResponse      []string
+		wantResponseTrunc bool
+		wantRequest       []string
+		wantRequestTrunc  bool
+	}{
+		{
+			name:         "captures every message within the default limits",
+			wantResponse: []string{"recv:1", "send:2", "send:3", "send:4"},
+		},
+		{
+			name:              "drops the messages beyond the message limit",
+			opts:              []logging.Option{logging.WithStreamPayloadLimit(2, 1<<10)},
+			wantResponse:      []string{"recv:1", "send:2"},
+			wantResponseTrunc: true,
+		},
+		{
+			name:              "drops the messages beyond the byte limit",
+			opts:              []logging.Option{logging.WithStreamPayloadLimit(100, 50)},
+			wantResponse:      []string{"recv:1", "send:2"},
+			wantResponseTrunc: true,
+		},
+		{
+			name:              "marks the payload as truncated when no message fits",
+			opts:              []logging.Option{logging.WithStreamPayloadLimit(100, 10)},
+			wantResponse:      []string{},
+			wantResponseTrunc: true,
+		},
+		{
+			name:              "numbers the messages in sequence across directions",
+			opts:              []logging.Option{logging.WithStreamPayloadLimit(2, 1<<10), logging.WithRequestPayload(1 << 10)},
+			wantResponse:      []string{"send:2", "send:3"},
+			wantResponseTrunc: true,
+			wantRequest:       []string{"recv:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			closer, client, h := logtest.NewServer(t, nil, tt.opts...)
+			defer closer()
+
+			stream, err := client.ServerStreamingEcho(context.Background(), &echopb.ServerStreamingEchoRequest{Message: "engage"})
+			if err != nil {
+				t.Fatalf("error was not <nil>: %s", err)
+			}
+			for {
+				if _, err := stream.Recv(); errors.Is(err, io.EOF) {
+					break
+				} else if err != nil {
+					t.Fatalf("error was not <nil>: %s", err)
+				}
+			}
+
+			r := logtest.AssertLogged(t, h, logtest.Path("/echo.v1.EchoService/ServerStreamingEcho"))
+
+			response, truncated := streamPayload(t, r, logging.ResponseKey)
+			if !reflect.DeepEqual(response, tt.wantResponse) {
+				t.Errorf("response messages are not equal: %v != %v", response, tt.wantResponse)
+			}
+			if truncated != tt.wantResponseTrunc {
+				t.Errorf("response truncation is not equal: %t != %t", truncated, tt.wantResponseTrunc)
+			}
+
+			request, truncated := streamPayload(t, r, logging.RequestKey)
+			if !reflect.DeepEqual(request, tt.wantRequest) {
+				t.Errorf("request messages are not equal: %v != %v", request, tt.wantRequest)
+			}
+			if truncated != tt.wantRequestTrunc {
+				t.Errorf("request truncation is not equal: %t != %t", truncated, tt.wantRequestTrunc)
+			}
+		})
+	}
+}
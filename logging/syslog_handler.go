@@ -0,0 +1,585 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// SyslogFacility is the facility of a syslog message, identifying the type of
+// program that logged it.
+type SyslogFacility int
+
+// Syslog facilities.
+// https://datatracker.ietf.org/doc/html/rfc5424#section-6.2.1
+const (
+	FacilityKern SyslogFacility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLpr
+	FacilityNews
+	FacilityUucp
+	FacilityCron
+	FacilityAuthpriv
+	FacilityFtp
+	_
+	_
+	_
+	_
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
+
+// Syslog severities.
+// https://datatracker.ietf.org/doc/html/rfc5424#section-6.2.1
+const (
+	syslogEmergency = iota
+	syslogAlert
+	syslogCritical
+	syslogError
+	syslogWarning
+	syslogNotice
+	syslogInformational
+	syslogDebug
+)
+
+// DefaultSyslogEnterpriseID is the private enterprise number used in the
+// structured data IDs written by a SyslogHandler. It is reserved for use in
+// documentation, so should be replaced using WithSyslogEnterpriseID by
+// organisations with their own number.
+const DefaultSyslogEnterpriseID = 32473
+
+// DefaultSyslogWriteTimeout is how long a SyslogHandler waits for a message to
+// be written, or for a connection to be established, before giving up on the
+// connection.
+const DefaultSyslogWriteTimeout = 10 * time.Second
+
+// ErrSyslogUnavailable is returned when a record is handled by a SyslogHandler
+// while it is waiting to reconnect to the syslog server.
+var ErrSyslogUnavailable = errors.New("logging: syslog server unavailable")
+
+// The bounds of the delay between attempts to reconnect to a syslog server,
+// which doubles with each failed attempt.
+const (
+	syslogMinBackoff = 100 * time.Millisecond
+	syslogMaxBackoff = time.Minute
+)
+
+// syslogRequestID is the name of the structured data element holding the
+// request attributes.
+const syslogRequestID = "request"
+
+// syslogNilValue is the value written in place of an empty header field.
+const syslogNilValue = "-"
+
+// syslogTimeFormat is the format of the timestamp of a syslog message, which
+// permits at most microsecond precision.
+const syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// syslogLocalAddrs are the paths of the local syslog socket on common
+// platforms.
+var syslogLocalAddrs = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// SyslogOption configures a SyslogHandler.
+type SyslogOption func(*SyslogHandler)
+
+// WithSyslogFacility sets the facility of each message. The default is
+// FacilityUser.
+func WithSyslogFacility(facility SyslogFacility) SyslogOption {
+	return func(h *SyslogHandler) {
+		h.facility = facility
+	}
+}
+
+// WithSyslogAppName sets the APP-NAME of each message. The default is the name
+// of the running program.
+func WithSyslogAppName(name string) SyslogOption {
+	return func(h *SyslogHandler) {
+		h.appName = name
+	}
+}
+
+// WithSyslogHostname sets the HOSTNAME of each message. The default is the
+// hostname reported by the kernel.
+func WithSyslogHostname(hostname string) SyslogOption {
+	return func(h *SyslogHandler) {
+		h.hostname = hostname
+	}
+}
+
+// WithSyslogWriteTimeout sets how long writing a message, or reconnecting to
+// the server, may block before the connection is abandoned, such that a
+// server which has stopped reading does not hold up every logger. The default
+// is DefaultSyslogWriteTimeout.
+func WithSyslogWriteTimeout(d time.Duration) SyslogOption {
+	return func(h *SyslogHandler) {
+		h.writeTimeout = d
+	}
+}
+
+// WithSyslogEnterpriseID sets the private enterprise number used in the
+// structured data IDs of each message, such as request@32473.
+func WithSyslogEnterpriseID(id int) SyslogOption {
+	return func(h *SyslogHandler) {
+		h.enterpriseID = id
+	}
+}
+
+// SyslogHandler is a handler that writes log messages to a syslog server in
+// the RFC 5424 format.
+//
+// The attributes of requests logged by the middleware and interceptors are
+// written as the parameters of a request structured data element, while all
+// other attributes are written as logfmt following the message. Levels are
+// mapped onto the syslog severity of the same name, with the trace level
+// written as debug.
+//
+// Should writing a message fail or time out the connection is re-established
+// and the message written once more. Should that fail too, further attempts
+// to reconnect are delayed by an exponential backoff, during which records
+// are dropped and ErrSyslogUnavailable is returned.
+type SyslogHandler struct {
+	conn  *syslogConn
+	level slog.Leveler
+
+	facility     SyslogFacility
+	appName      string
+	hostname     string
+	procID       string
+	enterpriseID int
+	writeTimeout time.Duration
+
+	// Request attributes go to the structured data whatever groups are open.
+	groups handlerGroups
+	fields []slog.Attr
+}
+
+// NewSyslogHandler returns a new SyslogHandler writing to the syslog server
+// at addr over the network, which may be "tcp", "udp" or "unixgram". Messages
+// sent over TCP are framed using octet counting. If network and addr are both
+// empty the handler writes to the local syslog socket.
+func NewSyslogHandler(network, addr string, level slog.Leveler, opts ...SyslogOption) (*SyslogHandler, error) {
+	h := &SyslogHandler{
+		level:        level,
+		facility:     FacilityUser,
+		appName:      filepath.Base(os.Args[0]),
+		procID:       strconv.Itoa(os.Getpid()),
+		enterpriseID: DefaultSyslogEnterpriseID,
+		writeTimeout: DefaultSyslogWriteTimeout,
+	}
+	h.hostname, _ = os.Hostname()
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	conn, err := dialSyslog(network, addr, h.writeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	h.conn = conn
+
+	return h, nil
+}
+
+// Enabled reports whether the handler handles records at the given level. The
+// handler ignores records whose level is lower.
+func (h *SyslogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return enabled(h.level, level)
+}
+
+// Handle formats its argument Record as an RFC 5424 message and writes it to
+// the syslog server.
+func (h *SyslogHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	fields := append(make([]slog.Attr, 0, len(h.fields)), h.fields...)
+	request := isRequestRecord(r)
+
+	// Separate out the attributes written as structured data.
+	r.Attrs(func(a slog.Attr) {
+		if isRequestMarker(a) {
+			return
+		}
+		if isSyslogRequestAttr(a, request) {
+			fields = append(fields, a)
+			return
+		}
+		attrs = append(attrs, a)
+	})
+
+	return h.conn.write(h.format(r, fields, h.groups.nest(attrs)))
+}
+
+// WithAttrs returns a new SyslogHandler whose attributes consists of h's
+// attributes followed by attrs. The request ID is always written to the
+// structured data, even when added within a group.
+func (h *SyslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := h.clone()
+
+	regular := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if isSyslogRequestAttr(a, false) {
+			h2.fields = append(h2.fields, a)
+			continue
+		}
+		regular = append(regular, a)
+	}
+
+	h2.groups = h2.groups.withAttrs(regular)
+	return h2
+}
+
+// WithGroup returns a new SyslogHandler whose attributes consists of h's
+// attributes followed by a group with the given name.
+func (h *SyslogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := h.clone()
+	h2.groups = h2.groups.withGroup(name)
+	return h2
+}
+
+// Close closes the connection to the syslog server, which is shared by every
+// handler derived from h. Records handled afterwards return ErrHandlerClosed.
+func (h *SyslogHandler) Close() error {
+	return h.conn.close()
+}
+
+// clone returns a copy of h sharing its connection.
+func (h *SyslogHandler) clone() *SyslogHandler {
+	h2 := *h
+	h2.fields = h.fields[:len(h.fields):len(h.fields)]
+	return &h2
+}
+
+// format returns r formatted as an RFC 5424 message.
+// https://datatracker.ietf.org/doc/html/rfc5424#section-6
+func (h *SyslogHandler) format(r slog.Record, fields, attrs []slog.Attr) []byte {
+	var buf bytes.Buffer
+
+	pri := int(h.facility)*8 + syslogSeverity(r.Level)
+	fmt.Fprintf(&buf, "<%d>1 ", pri)
+
+	if r.Time.IsZero() {
+		buf.WriteString(syslogNilValue)
+	} else {
+		buf.WriteString(r.Time.Format(syslogTimeFormat))
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeaderField(h.hostname, 255))
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeaderField(h.appName, 48))
+	buf.WriteByte(' ')
+	buf.WriteString(syslogHeaderField(h.procID, 128))
+	buf.WriteByte(' ')
+	buf.WriteString(syslogNilValue)
+	buf.WriteByte(' ')
+
+	if len(fields) == 0 {
+		buf.WriteString(syslogNilValue)
+	} else {
+		appendSyslogElement(&buf, syslogRequestID+"@"+strconv.Itoa(h.enterpriseID), fields)
+	}
+
+	// The message is followed by the remaining attributes as logfmt.
+	var msg bytes.Buffer
+	msg.WriteString(r.Message)
+	for _, a := range attrs {
+		appendLogfmtAttr(&msg, "", a)
+	}
+
+	if msg.Len() > 0 {
+		buf.WriteByte(' ')
+		buf.Write(msg.Bytes())
+	}
+
+	return buf.Bytes()
+}
+
+// syslogSeverity returns the syslog severity of the same name as level.
+func syslogSeverity(level slog.Level) int {
+	switch severityValue(slog.AnyValue(level)).String() {
+	case "EMERGENCY":
+		return syslogEmergency
+	case "ALERT":
+		return syslogAlert
+	case "CRITICAL":
+		return syslogCritical
+	case "ERROR":
+		return syslogError
+	case "WARNING":
+		return syslogWarning
+	case "NOTICE":
+		return syslogNotice
+	case "INFO":
+		return syslogInformational
+	default:
+		return syslogDebug
+	}
+}
+
+// isSyslogRequestAttr reports whether a is written to the request structured
+// data element. Only the request ID is considered unless a is an attribute of
+// a request record, since an application may log attributes of the same name
+// for other purposes.
+func isSyslogRequestAttr(a slog.Attr, request bool) bool {
+	switch {
+	case a.Key == RequestIDKey:
+		return true
+	case !request:
+		return false
+	case a.Key == CodeKey || a.Key == KindKey:
+		return true
+	default:
+		return isHTTPRequestAttr(a)
+	}
+}
+
+// appendSyslogElement appends a structured data element with the given ID
+// and attributes as its parameters. Groups are flattened, with the keys of
+// their attributes qualified by the group name.
+func appendSyslogElement(buf *bytes.Buffer, id string, attrs []slog.Attr) {
+	buf.WriteByte('[')
+	buf.WriteString(id)
+
+	var appendParam func(prefix string, a slog.Attr)
+	appendParam = func(prefix string, a slog.Attr) {
+		a.Value = a.Value.Resolve()
+
+		name := a.Key
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+
+		if a.Value.Kind() == slog.KindGroup {
+			for _, ga := range a.Value.Group() {
+				appendParam(name, ga)
+			}
+			return
+		}
+
+		buf.WriteByte(' ')
+		buf.WriteString(syslogParamName(name))
+		buf.WriteString(`="`)
+		buf.WriteString(syslogParamValue(a.Value))
+		buf.WriteByte('"')
+	}
+
+	for _, a := range attrs {
+		appendParam("", a)
+	}
+
+	buf.WriteByte(']')
+}
+
+// syslogHeaderField returns s as a header field of at most max printable
+// ASCII characters, or the nil value if s is empty.
+func syslogHeaderField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+
+	if s == "" {
+		return syslogNilValue
+	}
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
+
+// syslogParamName returns name as an SD-NAME, at most 32 printable ASCII
+// characters excluding '=', ' ', ']' and '"'.
+func syslogParamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if len(name) > 32 {
+		return name[:32]
+	}
+	return name
+}
+
+// syslogParamEscaper escapes the characters of a PARAM-VALUE that would
+// otherwise end the value.
+var syslogParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// syslogParamValue formats v as a PARAM-VALUE, escaping '"', '\' and ']'.
+func syslogParamValue(v slog.Value) string {
+	return syslogParamEscaper.Replace(v.String())
+}
+
+// syslogConn is a connection to a syslog server, which is re-established
+// should writing to it fail.
+type syslogConn struct {
+	network string
+	addr    string
+	timeout time.Duration
+
+	mu      sync.Mutex
+	conn    net.Conn
+	closed  bool
+	backoff time.Duration
+	retryAt time.Time
+}
+
+// dialSyslog connects to the syslog server at addr over the network, waiting
+// up to timeout for the connection to be established. If both are empty it
+// connects to the first local syslog socket found.
+func dialSyslog(network, addr string, timeout time.Duration) (*syslogConn, error) {
+	if network == "" && addr == "" {
+		for _, path := range syslogLocalAddrs {
+			if c, err := dialSyslog("unixgram", path, timeout); err == nil {
+				return c, nil
+			}
+		}
+		return nil, errors.New("logging: local syslog socket not found")
+	}
+
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unixgram":
+	default:
+		return nil, fmt.Errorf("logging: unsupported syslog network: %s", network)
+	}
+
+	c := &syslogConn{network: network, addr: addr, timeout: timeout}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// connect establishes a new connection, replacing any existing connection.
+// The caller must hold the lock.
+func (c *syslogConn) connect() error {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+
+	timeout := c.timeout
+	if timeout <= 0 {
+		timeout = DefaultSyslogWriteTimeout
+	}
+
+	conn, err := net.DialTimeout(c.network, c.addr, timeout)
+	if err != nil {
+		return fmt.Errorf("logging: failed to connect to syslog: %w", err)
+	}
+
+	c.conn = conn
+	return nil
+}
+
+// write writes a single message, reconnecting and trying once more if the
+// first attempt fails. Should the second attempt fail the message is dropped,
+// as are those written before the backoff has elapsed.
+func (c *syslogConn) write(msg []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrHandlerClosed
+	}
+
+	// Messages sent over a stream are framed using octet counting, while
+	// datagrams each hold a single message.
+	// https://datatracker.ietf.org/doc/html/rfc6587#section-3.4.1
+	if strings.HasPrefix(c.network, "tcp") {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+
+	if c.conn != nil {
+		if err := c.writeConn(msg); err == nil {
+			return nil
+		}
+	}
+
+	if time.Now().Before(c.retryAt) {
+		return ErrSyslogUnavailable
+	}
+
+	err := c.connect()
+	if err == nil {
+		err = c.writeConn(msg)
+	}
+	if err != nil {
+		c.backoff *= 2
+		if c.backoff < syslogMinBackoff {
+			c.backoff = syslogMinBackoff
+		} else if c.backoff > syslogMaxBackoff {
+			c.backoff = syslogMaxBackoff
+		}
+		c.retryAt = time.Now().Add(c.backoff)
+		return err
+	}
+
+	c.backoff = 0
+	return nil
+}
+
+// writeConn writes msg to the current connection within the write timeout.
+// Since part of the message may have been written, the connection is dropped
+// should the write fail. The caller must hold the lock.
+func (c *syslogConn) writeConn(msg []byte) error {
+	if c.timeout > 0 {
+		c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	}
+
+	if _, err := c.conn.Write(msg); err != nil {
+		c.conn.Close()
+		c.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+// close closes the connection.
+func (c *syslogConn) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.conn == nil {
+		return nil
+	}
+
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
@@ -0,0 +1,367 @@
+package logging_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+var syslogOptions = []logging.SyslogOption{
+	logging.WithSyslogFacility(logging.FacilityLocal0),
+	logging.WithSyslogAppName("bridge"),
+	logging.WithSyslogHostname("enterprise"),
+}
+
+// syslogRecord returns a record logged at a fixed time.
+func syslogRecord(level slog.Level, msg string, attrs ...slog.Attr) slog.Record {
+	r := slog.NewRecord(time.Date(2023, 4, 1, 12, 0, 0, 0, time.UTC), level, msg, 0)
+	r.AddAttrs(attrs...)
+	return r
+}
+
+// readOctetCounted reads a single message framed using octet counting.
+func readOctetCounted(t *testing.T, r *bufio.Reader) string {
+	length, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatalf("failed to read message length: %s", err)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+	if err != nil {
+		t.Fatalf("invalid message length: %q", length)
+	}
+
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		t.Fatalf("failed to read message: %s", err)
+	}
+
+	return string(msg)
+}
+
+// stripProcID replaces the PROCID header field, which varies between runs,
+// of a message. The message may begin with the HOSTNAME rather than the PRI.
+func stripProcID(msg string) string {
+	i := 4
+	if !strings.HasPrefix(msg, "<") {
+		i = 2
+	}
+
+	fields := strings.SplitN(msg, " ", i+2)
+	fields[i] = "PROCID"
+	return strings.Join(fields, " ")
+}
+
+func TestSyslogHandler_TCP(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- conn
+		}
+	}()
+
+	h, err := logging.NewSyslogHandler("tcp", ln.Addr().String(), logging.LevelTrace, syslogOptions...)
+	if err != nil {
+		t.Fatalf("error was not <nil>: %s", err)
+	}
+	defer h.Close()
+
+	conn := <-conns
+	r := bufio.NewReader(conn)
+
+	tests := []struct {
+		name   string
+		record slog.Record
+		want   string
+	}{
+		{
+			name:   "maps levels onto syslog severities",
+			record: syslogRecord(logging.LevelNotice, "hailing"),
+			want:   "<133>1 2023-04-01T12:00:00.000000Z enterprise bridge PROCID - - hailing",
+		},
+		{
+			name:   "maps the trace level onto the debug severity",
+			record: syslogRecord(logging.LevelTrace, "scanning"),
+			want:   "<135>1 2023-04-01T12:00:00.000000Z enterprise bridge PROCID - - scanning",
+		},
+		{
+			name:   "maps the emergency level onto the emergency severity",
+			record: syslogRecord(logging.LevelEmergency, "abandon ship"),
+			want:   "<128>1 2023-04-01T12:00:00.000000Z enterprise bridge PROCID - - abandon ship",
+		},
+		{
+			name: "writes request attributes as structured data",
+			record: syslogRecord(logging.LevelWarning, "",
+				logging.RequestMarker,
+				slog.Duration(logging.DurationKey, 1500*time.Millisecond),
+				slog.String(logging.MethodKey, "GET"),
+				slog.String(logging.PathKey, "/bridge"),
+				slog.Int(logging.StatusKey, 404),
+				slog.String(logging.UserAgentKey, `tricorder "mk] 2"`),
+			),
+			want: `<132>1 2023-04-01T12:00:00.000000Z enterprise bridge PROCID - [request@32473 duration="1.5s" method="GET" path="/bridge" status="404" userAgent="tricorder \"mk\] 2\""]`,
+		},
+		{
+			name: "writes other attributes as logfmt following the message",
+			record: syslogRecord(logging.LevelError, "warp core breach",
+				logging.RequestMarker,
+				slog.String(logging.MethodKey, "POST"),
+				slog.Group("ship", slog.String("name", "USS Enterprise")),
+			),
+			want: `<131>1 2023-04-01T12:00:00.000000Z enterprise bridge PROCID - [request@32473 method="POST"] warp core breach ship.name="USS Enterprise"`,
+		},
+		{
+			name: "writes the attributes of other records as logfmt",
+			record: syslogRecord(logging.LevelInfo, "job",
+				slog.String(logging.StatusKey, "done"),
+				slog.String(logging.PathKey, "/tmp/x"),
+			),
+			want: `<134>1 2023-04-01T12:00:00.000000Z enterprise bridge PROCID - - job status=done path=/tmp/x`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := h.Handle(context.Background(), tt.record); err != nil {
+				t.Fatalf("error was not <nil>: %s", err)
+			}
+
+			if got := stripProcID(readOctetCounted(t, r)); got != tt.want {
+				t.Errorf("messages are not equal:\n%s\n%s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("reconnects when the connection is lost", func(t *testing.T) {
+		conn.Close()
+
+		// Writing to a connection closed by the peer may succeed until the
+		// connection is reset, so keep writing until a new connection is made.
+		deadline := time.After(5 * time.Second)
+		for {
+			_ = h.Handle(context.Background(), syslogRecord(logging.LevelInfo, "engage"))
+
+			select {
+			case conn := <-conns:
+				defer conn.Close()
+
+				msg := readOctetCounted(t, bufio.NewReader(conn))
+				if !strings.HasSuffix(msg, " engage") {
+					t.Errorf("message was not written after reconnecting: %s", msg)
+				}
+				return
+			case <-deadline:
+				t.Fatal("handler did not reconnect")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+}
+
+func TestSyslogHandler_TCPStalled(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// Accept connections but never read from them, such that the buffers of
+	// each connection fill up. They are closed along with the listener.
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				for _, conn := range conns {
+					conn.Close()
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	opts := append(syslogOptions, logging.WithSyslogWriteTimeout(50*time.Millisecond))
+	h, err := logging.NewSyslogHandler("tcp", ln.Addr().String(), logging.LevelInfo, opts...)
+	if err != nil {
+		t.Fatalf("error was not <nil>: %s", err)
+	}
+	defer h.Close()
+
+	// The message is larger than the buffers of any one connection, so it may
+	// neither be written to the first connection nor the one replacing it.
+	r := syslogRecord(logging.LevelInfo, "engage", slog.String("payload", strings.Repeat("x", 32<<20)))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.Handle(context.Background(), r)
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("errors are not equal: %v != %s", err, os.ErrDeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("write to a stalled server did not time out")
+	}
+}
+
+func TestSyslogHandler_TCPUnavailable(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conns := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			conns <- conn
+		}
+	}()
+
+	h, err := logging.NewSyslogHandler("tcp", ln.Addr().String(), logging.LevelInfo, syslogOptions...)
+	if err != nil {
+		t.Fatalf("error was not <nil>: %s", err)
+	}
+	defer h.Close()
+
+	// The server goes away along with its connection.
+	ln.Close()
+	(<-conns).Close()
+
+	// Writing to a connection closed by the peer may succeed until the
+	// connection is reset, after which reconnecting fails.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err := h.Handle(context.Background(), syslogRecord(logging.LevelInfo, "engage"))
+		if err != nil {
+			if errors.Is(err, logging.ErrSyslogUnavailable) {
+				t.Fatalf("error was returned before reconnecting: %s", err)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("write to an unavailable server did not fail")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Further records are dropped without reconnecting until the backoff
+	// has elapsed.
+	if err := h.Handle(context.Background(), syslogRecord(logging.LevelInfo, "engage")); !errors.Is(err, logging.ErrSyslogUnavailable) {
+		t.Errorf("errors are not equal: %v != %s", err, logging.ErrSyslogUnavailable)
+	}
+}
+
+func TestSyslogHandler_UDP(t *testing.T) {
+	t.Parallel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	h, err := logging.NewSyslogHandler("udp", pc.LocalAddr().String(), logging.LevelInfo, syslogOptions...)
+	if err != nil {
+		t.Fatalf("error was not <nil>: %s", err)
+	}
+	defer h.Close()
+
+	logging.New(h).Info(context.Background(), "engage")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("error was not <nil>: %s", err)
+	}
+
+	fields := strings.SplitN(string(buf[:n]), " ", 3)
+	if want := "<134>1"; fields[0] != want {
+		t.Errorf("headers are not equal: %s != %s", fields[0], want)
+	}
+	if got, want := stripProcID(fields[2]), "enterprise bridge PROCID - - engage"; got != want {
+		t.Errorf("messages are not equal: %s != %s", got, want)
+	}
+}
+
+func TestSyslogHandler_Unixgram(t *testing.T) {
+	t.Parallel()
+
+	addr := filepath.Join(t.TempDir(), "log")
+
+	pc, err := net.ListenPacket("unixgram", addr)
+	if err != nil {
+		t.Skipf("unix datagram sockets are not supported: %s", err)
+	}
+	defer pc.Close()
+
+	h, err := logging.NewSyslogHandler("unixgram", addr, logging.LevelInfo, syslogOptions...)
+	if err != nil {
+		t.Fatalf("error was not <nil>: %s", err)
+	}
+
+	logger := logging.New(h).With(logging.RequestIDKey, "NCC-1701-D")
+	logger.Info(context.Background(), "engage")
+
+	buf := make([]byte, 2048)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("error was not <nil>: %s", err)
+	}
+
+	if msg := string(buf[:n]); !strings.HasSuffix(msg, ` [request@32473 requestId="NCC-1701-D"] engage`) {
+		t.Errorf("message was not written: %s", msg)
+	}
+
+	if err := h.Close(); err != nil {
+		t.Fatalf("error was not <nil>: %s", err)
+	}
+
+	if err := h.Handle(context.Background(), syslogRecord(logging.LevelInfo, "engage")); !errors.Is(err, logging.ErrHandlerClosed) {
+		t.Errorf("errors are not equal: %v != %s", err, logging.ErrHandlerClosed)
+	}
+}
+
+func TestNewSyslogHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns an error for an unsupported network", func(t *testing.T) {
+		if _, err := logging.NewSyslogHandler("ip", "127.0.0.1", logging.LevelInfo); err == nil {
+			t.Error("error was <nil>")
+		}
+	})
+}
@@ -0,0 +1,290 @@
+package logging
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Trace context propagation headers.
+const (
+	// TraceParentHeader is the W3C Trace Context header identifying the trace
+	// and parent span of a request.
+	// https://www.w3.org/TR/trace-context/#traceparent-header
+	TraceParentHeader = "traceparent"
+
+	// TraceStateHeader is the W3C Trace Context header carrying vendor
+	// specific trace information.
+	// https://www.w3.org/TR/trace-context/#tracestate-header
+	TraceStateHeader = "tracestate"
+
+	// CloudTraceContextHeader is the legacy Google Cloud trace header.
+	// https://cloud.google.com/trace/docs/trace-context#legacy-http-header
+	CloudTraceContextHeader = "X-Cloud-Trace-Context"
+)
+
+// ErrInvalidTraceContext is returned when a trace header cannot be parsed.
+var ErrInvalidTraceContext = errors.New("logging: invalid trace context")
+
+// TraceContext identifies the trace, and the span within it, that a request
+// belongs to.
+type TraceContext struct {
+	// TraceID is the 32 character lowercase hex encoded trace ID.
+	TraceID string
+	// SpanID is the 16 character lowercase hex encoded span ID.
+	SpanID string
+	// Sampled reports whether the caller may have recorded the trace.
+	Sampled bool
+	// TraceState is the vendor specific trace information, if any.
+	TraceState string
+}
+
+type traceContextKey struct{}
+
+// TraceFromContext returns the TraceContext value stored in ctx, if any.
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+// NewTraceContext returns a new Context that carries a TraceContext.
+func NewTraceContext(parent context.Context, tc TraceContext) context.Context {
+	return context.WithValue(parent, traceContextKey{}, tc)
+}
+
+// ParseTraceParent parses the value of a W3C traceparent header, of the form
+// "version-traceid-parentid-flags".
+func ParseTraceParent(s string) (TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, ErrInvalidTraceContext
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	// Version ff is forbidden, and version 00 defines exactly four fields.
+	// Later versions may append fields which are ignored.
+	if !isHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return TraceContext{}, ErrInvalidTraceContext
+	}
+
+	if !isHex(traceID, 32) || isZero(traceID) || !isHex(spanID, 16) || isZero(spanID) || !isHex(flags, 2) {
+		return TraceContext{}, ErrInvalidTraceContext
+	}
+
+	b, _ := hex.DecodeString(flags)
+
+	return TraceContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Sampled: b[0]&0x01 == 0x01,
+	}, nil
+}
+
+// ParseCloudTraceContext parses the value of an X-Cloud-Trace-Context header,
+// of the form "TRACE_ID/SPAN_ID;o=OPTIONS", where the span ID is a decimal
+// number. Both the span ID and options are optional.
+func ParseCloudTraceContext(s string) (TraceContext, error) {
+	s = strings.TrimSpace(s)
+
+	var opts string
+	if i := strings.Index(s, ";"); i >= 0 {
+		s, opts = s[:i], s[i+1:]
+	}
+
+	traceID, spanID := s, ""
+	if i := strings.Index(s, "/"); i >= 0 {
+		traceID, spanID = s[:i], s[i+1:]
+	}
+
+	traceID = strings.ToLower(traceID)
+	if !isHex(traceID, 32) || isZero(traceID) {
+		return TraceContext{}, ErrInvalidTraceContext
+	}
+
+	tc := TraceContext{TraceID: traceID}
+
+	if spanID != "" {
+		id, err := strconv.ParseUint(spanID, 10, 64)
+		if err != nil {
+			return TraceContext{}, ErrInvalidTraceContext
+		}
+
+		if id != 0 {
+			tc.SpanID = fmt.Sprintf("%016x", id)
+		}
+	}
+
+	tc.Sampled = opts == "o=1"
+
+	return tc, nil
+}
+
+// TraceFromHTTPHeader extracts the trace context from the headers of a HTTP
+// request. The W3C traceparent header takes precedence over the
+// X-Cloud-Trace-Context header.
+func TraceFromHTTPHeader(h http.Header) (TraceContext, bool) {
+	return traceFromValues(h.Get(TraceParentHeader), h.Get(TraceStateHeader), h.Get(CloudTraceContextHeader))
+}
+
+// TraceFromMetadata extracts the trace context from gRPC metadata. The W3C
+// traceparent key takes precedence over the X-Cloud-Trace-Context key.
+func TraceFromMetadata(md metadata.MD) (TraceContext, bool) {
+	return traceFromValues(firstValue(md, TraceParentHeader), firstValue(md, TraceStateHeader), firstValue(md, CloudTraceContextHeader))
+}
+
+func traceFromValues(traceParent, traceState, cloudTraceContext string) (TraceContext, bool) {
+	if traceParent != "" {
+		if tc, err := ParseTraceParent(traceParent); err == nil {
+			tc.TraceState = traceState
+			return tc, true
+		}
+	}
+
+	if cloudTraceContext != "" {
+		if tc, err := ParseCloudTraceContext(cloudTraceContext); err == nil {
+			return tc, true
+		}
+	}
+
+	return TraceContext{}, false
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+// GoogleCloudTraceHandler returns an AttrHandler formatting the trace ID
+// stored in the context as the resource name expected by Google Cloud
+// Logging, "projects/<projectID>/traces/<traceID>".
+func GoogleCloudTraceHandler(projectID string) AttrHandler {
+	return func(ctx context.Context) slog.Value {
+		tc, ok := TraceFromContext(ctx)
+		if !ok {
+			return NilValue
+		}
+
+		return slog.StringValue("projects/" + projectID + "/traces/" + tc.TraceID)
+	}
+}
+
+// GoogleCloudTraceSampledHandler returns an AttrHandler reporting whether the
+// trace stored in the context was sampled.
+func GoogleCloudTraceSampledHandler() AttrHandler {
+	return func(ctx context.Context) slog.Value {
+		tc, ok := TraceFromContext(ctx)
+		if !ok {
+			return NilValue
+		}
+
+		return slog.BoolValue(tc.Sampled)
+	}
+}
+
+// TraceIDHandler returns an AttrHandler returning the trace ID stored in the
+// context, hex encoded as defined by W3C Trace Context.
+func TraceIDHandler() AttrHandler {
+	return func(ctx context.Context) slog.Value {
+		tc, ok := TraceFromContext(ctx)
+		if !ok {
+			return NilValue
+		}
+
+		return slog.StringValue(tc.TraceID)
+	}
+}
+
+// SpanIDHandler returns an AttrHandler returning the span ID stored in the
+// context. Span IDs are hex encoded, which is the format expected by both
+// Google Cloud Logging and AWS X-Ray.
+func SpanIDHandler() AttrHandler {
+	return func(ctx context.Context) slog.Value {
+		tc, ok := TraceFromContext(ctx)
+		if !ok || tc.SpanID == "" {
+			return NilValue
+		}
+
+		return slog.StringValue(tc.SpanID)
+	}
+}
+
+// XRayTraceHandler returns an AttrHandler formatting the trace ID stored in
+// the context as an AWS X-Ray trace ID, "1-<8 hex digits>-<24 hex digits>".
+// Trace IDs that are not 32 hex digits are omitted.
+// https://docs.aws.amazon.com/xray/latest/devguide/xray-api-sendingdata.html#xray-api-traceids
+func XRayTraceHandler() AttrHandler {
+	return func(ctx context.Context) slog.Value {
+		tc, ok := TraceFromContext(ctx)
+		if !ok || !isHex(tc.TraceID, 32) {
+			return NilValue
+		}
+
+		return slog.StringValue("1-" + tc.TraceID[:8] + "-" + tc.TraceID[8:])
+	}
+}
+
+// DatadogTraceIDHandler returns an AttrHandler formatting the trace ID stored
+// in the context as a Datadog trace ID, that is the lower 64 bits of the ID as
+// a decimal number. Trace IDs that are not 32 hex digits are omitted.
+// https://docs.datadoghq.com/tracing/other_telemetry/connect_logs_and_traces/opentelemetry/
+func DatadogTraceIDHandler() AttrHandler {
+	return func(ctx context.Context) slog.Value {
+		tc, ok := TraceFromContext(ctx)
+		if !ok || !isHex(tc.TraceID, 32) {
+			return NilValue
+		}
+
+		return datadogID(tc.TraceID[16:])
+	}
+}
+
+// DatadogSpanIDHandler returns an AttrHandler formatting the span ID stored in
+// the context as a Datadog span ID, that is as a decimal number.
+func DatadogSpanIDHandler() AttrHandler {
+	return func(ctx context.Context) slog.Value {
+		tc, ok := TraceFromContext(ctx)
+		if !ok || tc.SpanID == "" {
+			return NilValue
+		}
+
+		return datadogID(tc.SpanID)
+	}
+}
+
+func datadogID(s string) slog.Value {
+	id, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return NilValue
+	}
+
+	return slog.StringValue(strconv.FormatUint(id, 10))
+}
@@ -0,0 +1,183 @@
+package logging_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"golang.org/x/exp/slog"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/kapetndev/connect/logging"
+)
+
+const (
+	traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID  = "00f067aa0ba902b7"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parses a valid traceparent header", func(t *testing.T) {
+		tc, err := logging.ParseTraceParent("00-" + traceID + "-" + spanID + "-01")
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		expected := logging.TraceContext{TraceID: traceID, SpanID: spanID, Sampled: true}
+		if tc != expected {
+			t.Errorf("trace contexts are not equal: %+v != %+v", tc, expected)
+		}
+	})
+
+	t.Run("returns an error when the traceparent header is invalid", func(t *testing.T) {
+		for _, s := range []string{
+			"",
+			"00-" + traceID + "-" + spanID,
+			"ff-" + traceID + "-" + spanID + "-01",
+			"00-00000000000000000000000000000000-" + spanID + "-01",
+			"00-" + traceID + "-0000000000000000-01",
+			"00-" + traceID + "-" + spanID + "-01-extra",
+		} {
+			if _, err := logging.ParseTraceParent(s); err == nil {
+				t.Errorf("error was <nil> for %q", s)
+			}
+		}
+	})
+}
+
+func TestParseCloudTraceContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parses a valid X-Cloud-Trace-Context header", func(t *testing.T) {
+		tc, err := logging.ParseCloudTraceContext(traceID + "/67667974448284343;o=1")
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		expected := logging.TraceContext{TraceID: traceID, SpanID: "00f067aa0ba902b7", Sampled: true}
+		if tc != expected {
+			t.Errorf("trace contexts are not equal: %+v != %+v", tc, expected)
+		}
+	})
+
+	t.Run("parses a X-Cloud-Trace-Context header without a span", func(t *testing.T) {
+		tc, err := logging.ParseCloudTraceContext(traceID)
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		expected := logging.TraceContext{TraceID: traceID}
+		if tc != expected {
+			t.Errorf("trace contexts are not equal: %+v != %+v", tc, expected)
+		}
+	})
+
+	t.Run("returns an error when the X-Cloud-Trace-Context header is invalid", func(t *testing.T) {
+		for _, s := range []string{"", "abc/1;o=1", traceID + "/span;o=1"} {
+			if _, err := logging.ParseCloudTraceContext(s); err == nil {
+				t.Errorf("error was <nil> for %q", s)
+			}
+		}
+	})
+}
+
+func TestTraceFromHTTPHeader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("prefers the traceparent header over X-Cloud-Trace-Context", func(t *testing.T) {
+		h := http.Header{}
+		h.Set(logging.TraceParentHeader, "00-"+traceID+"-"+spanID+"-00")
+		h.Set(logging.TraceStateHeader, "congo=t61rcWkgMzE")
+		h.Set(logging.CloudTraceContextHeader, "105445aa7843bc8bf206b12000100000/1;o=1")
+
+		tc, ok := logging.TraceFromHTTPHeader(h)
+		if !ok {
+			t.Fatal("trace context not found")
+		}
+
+		expected := logging.TraceContext{TraceID: traceID, SpanID: spanID, TraceState: "congo=t61rcWkgMzE"}
+		if tc != expected {
+			t.Errorf("trace contexts are not equal: %+v != %+v", tc, expected)
+		}
+	})
+
+	t.Run("falls back to the X-Cloud-Trace-Context header", func(t *testing.T) {
+		h := http.Header{}
+		h.Set(logging.CloudTraceContextHeader, traceID+"/1;o=1")
+
+		tc, ok := logging.TraceFromHTTPHeader(h)
+		if !ok {
+			t.Fatal("trace context not found")
+		}
+
+		expected := logging.TraceContext{TraceID: traceID, SpanID: "0000000000000001", Sampled: true}
+		if tc != expected {
+			t.Errorf("trace contexts are not equal: %+v != %+v", tc, expected)
+		}
+	})
+}
+
+func TestTraceFromMetadata(t *testing.T) {
+	t.Parallel()
+
+	t.Run("extracts the trace context from gRPC metadata", func(t *testing.T) {
+		md := metadata.Pairs(logging.TraceParentHeader, "00-"+traceID+"-"+spanID+"-01")
+
+		tc, ok := logging.TraceFromMetadata(md)
+		if !ok {
+			t.Fatal("trace context not found")
+		}
+
+		expected := logging.TraceContext{TraceID: traceID, SpanID: spanID, Sampled: true}
+		if tc != expected {
+			t.Errorf("trace contexts are not equal: %+v != %+v", tc, expected)
+		}
+	})
+}
+
+func TestTraceAttrHandlers(t *testing.T) {
+	t.Parallel()
+
+	ctx := logging.NewTraceContext(context.Background(), logging.TraceContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Sampled: true,
+	})
+
+	t.Run("formats values from the trace context", func(t *testing.T) {
+		for name, tt := range map[string]struct {
+			handler  logging.AttrHandler
+			expected slog.Value
+		}{
+			"google trace":   {logging.GoogleCloudTraceHandler("enterprise"), slog.StringValue("projects/enterprise/traces/" + traceID)},
+			"google sampled": {logging.GoogleCloudTraceSampledHandler(), slog.BoolValue(true)},
+			"span":           {logging.SpanIDHandler(), slog.StringValue(spanID)},
+			"trace":          {logging.TraceIDHandler(), slog.StringValue(traceID)},
+			"xray trace":     {logging.XRayTraceHandler(), slog.StringValue("1-4bf92f35-77b34da6a3ce929d0e0e4736")},
+			"datadog trace":  {logging.DatadogTraceIDHandler(), slog.StringValue("11803532876627986230")},
+			"datadog span":   {logging.DatadogSpanIDHandler(), slog.StringValue("67667974448284343")},
+		} {
+			if v := tt.handler(ctx); !v.Equal(tt.expected) {
+				t.Errorf("%s values are not equal: %s != %s", name, v, tt.expected)
+			}
+		}
+	})
+
+	t.Run("returns a nil value for a malformed trace ID", func(t *testing.T) {
+		for _, id := range []string{"", "abc", "4bf92f3577b34da6a3ce929d0e0e473g"} {
+			ctx := logging.NewTraceContext(context.Background(), logging.TraceContext{TraceID: id})
+			if v := logging.XRayTraceHandler()(ctx); v != logging.NilValue {
+				t.Errorf("value was not nil for %q: %s", id, v)
+			}
+		}
+	})
+
+	t.Run("returns a nil value when there is no trace context", func(t *testing.T) {
+		if v := logging.SpanIDHandler()(context.Background()); v != logging.NilValue {
+			t.Errorf("value was not nil: %s", v)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package requestid
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/kapetndev/connect/transport"
+)
+
+// UnaryServerInterceptor returns a unary server interceptor that reads the
+// request ID from the x-request-id metadata key, generating one if it is not
+// present, and injects it into the context. The ID is also sent in the
+// response header.
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := applyOptions(opts)
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := o.requestID(incomingID(ctx))
+
+		if err := grpc.SetHeader(ctx, metadata.Pairs(MetadataKey, id)); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		return handler(NewContext(ctx, id), req)
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor that reads the
+// request ID from the x-request-id metadata key, generating one if it is not
+// present, and injects it into the stream context. The ID is also sent in the
+// response header.
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := applyOptions(opts)
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		id := o.requestID(incomingID(ctx))
+
+		if err := ss.SetHeader(metadata.Pairs(MetadataKey, id)); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		ss, err := transport.NewServerStreamWithContext(NewContext(ctx, id), ss)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// incomingID returns the request ID from the incoming metadata of a call, if
+// any.
+func incomingID(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if v := md.Get(MetadataKey); len(v) > 0 {
+		return v[0]
+	}
+
+	return ""
+}
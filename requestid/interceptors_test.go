@@ -0,0 +1,115 @@
+package requestid_test
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/kapetndev/connect/logging/logtest"
+	"github.com/kapetndev/connect/requestid"
+	echopb "github.com/kapetndev/connect/testdata/echo/v1"
+	"github.com/kapetndev/grpctest"
+)
+
+type echoServer struct {
+	logtest.EchoServer
+}
+
+// Echo responds with the request ID found in the context.
+func (s *echoServer) Echo(ctx context.Context, in *echopb.EchoRequest) (*echopb.EchoResponse, error) {
+	id, _ := requestid.FromContext(ctx)
+	return &echopb.EchoResponse{Message: id}, nil
+}
+
+// ServerStreamingEcho responds with the request ID found in the stream
+// context.
+func (s *echoServer) ServerStreamingEcho(in *echopb.ServerStreamingEchoRequest, ss echopb.EchoService_ServerStreamingEchoServer) error {
+	id, _ := requestid.FromContext(ss.Context())
+	return ss.Send(&echopb.ServerStreamingEchoResponse{Message: id})
+}
+
+func setupRequestIDServer(t *testing.T, opts ...requestid.Option) (grpctest.Closer, echopb.EchoServiceClient) {
+	return logtest.Serve(t, &echoServer{}, []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			requestid.UnaryServerInterceptor(opts...),
+		),
+		grpc.ChainStreamInterceptor(
+			requestid.StreamServerInterceptor(opts...),
+		),
+	})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("uses the request ID of the incoming call", func(t *testing.T) {
+		closer, client := setupRequestIDServer(t)
+		defer closer()
+
+		var header metadata.MD
+		ctx := metadata.AppendToOutgoingContext(context.Background(), requestid.MetadataKey, "NCC-1701-D")
+
+		resp, err := client.Echo(ctx, &echopb.EchoRequest{}, grpc.Header(&header))
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		if resp.Message != "NCC-1701-D" {
+			t.Errorf("request IDs are not equal: %s != %s", resp.Message, "NCC-1701-D")
+		}
+		if v := header.Get(requestid.MetadataKey); len(v) != 1 || v[0] != resp.Message {
+			t.Errorf("response headers are not equal: %v != %s", v, resp.Message)
+		}
+	})
+
+	t.Run("generates a request ID when the incoming call has none", func(t *testing.T) {
+		closer, client := setupRequestIDServer(t, requestid.WithGenerator(func() string { return "NCC-1701-E" }))
+		defer closer()
+
+		var header metadata.MD
+
+		resp, err := client.Echo(context.Background(), &echopb.EchoRequest{}, grpc.Header(&header))
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		if resp.Message != "NCC-1701-E" {
+			t.Errorf("request IDs are not equal: %s != %s", resp.Message, "NCC-1701-E")
+		}
+		if v := header.Get(requestid.MetadataKey); len(v) != 1 || v[0] != resp.Message {
+			t.Errorf("response headers are not equal: %v != %s", v, resp.Message)
+		}
+	})
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("uses the request ID of the incoming stream", func(t *testing.T) {
+		closer, client := setupRequestIDServer(t)
+		defer closer()
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), requestid.MetadataKey, "NCC-1701-D")
+
+		stream, err := client.ServerStreamingEcho(ctx, &echopb.ServerStreamingEchoRequest{})
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		if resp.Message != "NCC-1701-D" {
+			t.Errorf("request IDs are not equal: %s != %s", resp.Message, "NCC-1701-D")
+		}
+
+		header, err := stream.Header()
+		if err != nil {
+			t.Fatalf("error was not <nil>: %s", err)
+		}
+		if v := header.Get(requestid.MetadataKey); len(v) != 1 || v[0] != resp.Message {
+			t.Errorf("response headers are not equal: %v != %s", v, resp.Message)
+		}
+	})
+}
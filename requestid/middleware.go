@@ -0,0 +1,22 @@
+package requestid
+
+import (
+	"net/http"
+
+	"github.com/kapetndev/connect/transport"
+)
+
+// Handler returns a middleware that reads the request ID from the X-Request-Id
+// header, generating one if it is not present, and injects it into the
+// request context. The ID is also set on the response header.
+func Handler(opts ...Option) transport.Middleware {
+	o := applyOptions(opts)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			id := o.requestID(r.Header.Get(Header))
+
+			w.Header().Set(Header, id)
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package requestid_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kapetndev/connect/requestid"
+)
+
+func serveHTTP(r *http.Request, opts ...requestid.Option) (*httptest.ResponseRecorder, string) {
+	var id string
+
+	w := httptest.NewRecorder()
+	requestid.Handler(opts...)(func(w http.ResponseWriter, r *http.Request) {
+		id, _ = requestid.FromContext(r.Context())
+	})(w, r)
+
+	return w, id
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("uses the request ID of the incoming request", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(requestid.Header, "NCC-1701-D")
+
+		w, id := serveHTTP(r)
+		if id != "NCC-1701-D" {
+			t.Errorf("request IDs are not equal: %s != %s", id, "NCC-1701-D")
+		}
+		if h := w.Header().Get(requestid.Header); h != id {
+			t.Errorf("response headers are not equal: %s != %s", h, id)
+		}
+	})
+
+	t.Run("generates a request ID when the incoming request has none", func(t *testing.T) {
+		w, id := serveHTTP(httptest.NewRequest(http.MethodGet, "/", nil))
+		if len(id) != 26 {
+			t.Errorf("request ID was not generated: %q", id)
+		}
+		if h := w.Header().Get(requestid.Header); h != id {
+			t.Errorf("response headers are not equal: %s != %s", h, id)
+		}
+	})
+
+	t.Run("generates a request ID when the incoming request ID is invalid", func(t *testing.T) {
+		for _, incoming := range []string{"warp core breach", strings.Repeat("a", 129)} {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set(requestid.Header, incoming)
+
+			if _, id := serveHTTP(r); id == incoming {
+				t.Errorf("invalid request ID was used: %q", id)
+			}
+		}
+	})
+
+	t.Run("uses the generator to create request IDs", func(t *testing.T) {
+		generator := func() string { return "NCC-1701-E" }
+
+		_, id := serveHTTP(httptest.NewRequest(http.MethodGet, "/", nil), requestid.WithGenerator(generator))
+		if id != "NCC-1701-E" {
+			t.Errorf("request IDs are not equal: %s != %s", id, "NCC-1701-E")
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package requestid
+
+var defaultOptions = options{
+	generator: NewID,
+}
+
+// options describe the full set of options that may be configured to
+// influence request ID behaviour.
+type options struct {
+	generator func() string
+}
+
+// Option is a function that can configure one or more request ID options.
+type Option func(*options)
+
+// WithGenerator returns a request ID option to customise how IDs are
+// generated for requests that arrive without one.
+func WithGenerator(f func() string) Option {
+	return func(o *options) {
+		o.generator = f
+	}
+}
+
+func applyOptions(opts []Option) options {
+	cfg := defaultOptions
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// requestID returns the incoming ID if it is valid, otherwise a generated ID.
+func (o options) requestID(incoming string) string {
+	if valid(incoming) {
+		return incoming
+	}
+	return o.generator()
+}
@@ -0,0 +1,123 @@
+// Package requestid provides middleware and interceptors that assign each
+// request an ID, allowing the log entries and responses of a single request to
+// be correlated.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// Header is the HTTP header from which the ID of an incoming request is read,
+// and to which the ID is written on the response.
+const Header = "X-Request-Id"
+
+// MetadataKey is the gRPC metadata key from which the ID of an incoming call
+// is read, and to which the ID is written in the response header.
+const MetadataKey = "x-request-id"
+
+// maxLength is the maximum length of an incoming request ID. Longer IDs are
+// replaced with a generated ID.
+const maxLength = 128
+
+type requestIDContextKey struct{}
+
+// FromContext returns the request ID stored in ctx, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDContextKey{}).(string)
+	return id, ok
+}
+
+// NewContext returns a new Context that carries a request ID.
+func NewContext(parent context.Context, id string) context.Context {
+	return context.WithValue(parent, requestIDContextKey{}, id)
+}
+
+// valid reports whether an incoming request ID may be used as is. Only
+// printable ASCII is allowed so the ID may be safely logged and echoed back
+// in a header.
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// crockford is the Crockford base32 alphabet, which sorts in the same order
+// as the values it encodes.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var defaultGenerator = &generator{}
+
+// NewID returns a new unique ID. IDs are 26 character strings in the ULID
+// format, made up of a millisecond timestamp followed by 80 random bits, such
+// that IDs sort in the order they were generated.
+func NewID() string {
+	return defaultGenerator.next(time.Now())
+}
+
+// generator generates monotonically increasing IDs. When more than one ID is
+// generated within the same millisecond the random part of the previous ID is
+// incremented rather than generated anew.
+type generator struct {
+	mu      sync.Mutex
+	ms      uint64
+	entropy [10]byte
+}
+
+func (g *generator) next(t time.Time) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(t.UnixMilli())
+	if ms > g.ms || !g.increment() {
+		g.ms = ms
+		if _, err := rand.Read(g.entropy[:]); err != nil {
+			panic("requestid: failed to read random bytes: " + err.Error())
+		}
+	}
+
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], g.ms<<16)
+	copy(id[6:], g.entropy[:])
+
+	return encode(id)
+}
+
+// increment adds one to the random part of the ID, reporting false if it
+// overflowed.
+func (g *generator) increment() bool {
+	for i := len(g.entropy) - 1; i >= 0; i-- {
+		g.entropy[i]++
+		if g.entropy[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encode encodes the 128 bits of id as 26 characters of Crockford base32, the
+// first of which holds only the top 3 bits.
+func encode(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var b [26]byte
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(b[:])
+}
@@ -0,0 +1,61 @@
+package requestid_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/kapetndev/connect/requestid"
+)
+
+func TestNewID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("generates IDs in the ULID format", func(t *testing.T) {
+		id := requestid.NewID()
+		if len(id) != 26 {
+			t.Errorf("lengths are not equal: %d != %d", len(id), 26)
+		}
+	})
+
+	t.Run("generates unique IDs that sort in the order they were generated", func(t *testing.T) {
+		ids := make([]string, 1000)
+		for i := range ids {
+			ids[i] = requestid.NewID()
+		}
+
+		if !sort.StringsAreSorted(ids) {
+			t.Error("IDs are not sorted")
+		}
+
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if seen[id] {
+				t.Fatalf("ID was generated more than once: %s", id)
+			}
+			seen[id] = true
+		}
+	})
+}
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("returns false when the context has no request ID", func(t *testing.T) {
+		if id, ok := requestid.FromContext(context.Background()); ok {
+			t.Errorf("unexpected request ID: %s", id)
+		}
+	})
+
+	t.Run("returns the request ID stored in the context", func(t *testing.T) {
+		ctx := requestid.NewContext(context.Background(), "NCC-1701-D")
+
+		id, ok := requestid.FromContext(ctx)
+		if !ok {
+			t.Fatal("request ID was not found")
+		}
+		if id != "NCC-1701-D" {
+			t.Errorf("request IDs are not equal: %s != %s", id, "NCC-1701-D")
+		}
+	})
+}
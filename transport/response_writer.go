@@ -2,14 +2,15 @@ package transport
 
 import "net/http"
 
-// ResponseWriter is used by a HTTP handler to construct a HTTP response. Both
-// the status code and payload are captured by this type.
+// ResponseWriter is used by a HTTP handler to construct a HTTP response. The
+// status code, payload and size of the response are captured by this type.
 type ResponseWriter struct {
 	http.ResponseWriter
 
 	// Captured values.
 	statusCode int
 	payload    []byte
+	size       int
 }
 
 // NewResponseWriter returns a new ResponseWriter.
@@ -29,7 +30,10 @@ func (w *ResponseWriter) WriteHeader(code int) {
 // Write writes the data to the connection as part of a HTTP reply.
 func (w *ResponseWriter) Write(payload []byte) (int, error) {
 	w.payload = payload
-	return w.ResponseWriter.Write(payload)
+
+	n, err := w.ResponseWriter.Write(payload)
+	w.size += n
+	return n, err
 }
 
 // StatusCode returns the status code last written to the writer.
@@ -41,3 +45,8 @@ func (w *ResponseWriter) StatusCode() int {
 func (w *ResponseWriter) Payload() []byte {
 	return w.payload
 }
+
+// Size returns the total number of bytes written to the writer.
+func (w *ResponseWriter) Size() int {
+	return w.size
+}
@@ -36,4 +36,18 @@ func TestResponseWriter(t *testing.T) {
 			t.Errorf("payloads are not equal: %s != %s", rw.Payload(), expectedPayload)
 		}
 	})
+
+	t.Run("captures the total size of the response", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		rw := transport.NewResponseWriter(w)
+
+		// Capture the size over multiple writes.
+		rw.Write([]byte("hello, "))
+		rw.Write([]byte("world"))
+
+		expectedSize := 12
+		if rw.Size() != expectedSize {
+			t.Errorf("sizes are not equal: %d != %d", rw.Size(), expectedSize)
+		}
+	})
 }